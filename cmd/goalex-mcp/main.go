@@ -0,0 +1,94 @@
+// Command goalex-mcp serves OpenAlex to language-model agents over the Model
+// Context Protocol.
+//
+// Usage:
+//
+//	goalex-mcp [flags]
+//
+// By default the server speaks over stdio, as expected by agents that launch
+// it as a subprocess. With -http it listens on the given address and serves
+// the protocol at /mcp.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/Sunhill666/goalex/internal/cli"
+	"github.com/Sunhill666/goalex/pkg/core"
+	"github.com/Sunhill666/goalex/pkg/mcp"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:])
+	stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goalex-mcp: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("goalex-mcp", flag.ContinueOnError)
+	addr := fs.String("http", "", "listen on this address, e.g. localhost:8080, instead of stdio")
+	mailTo := fs.String("mailto", os.Getenv(cli.EnvMailTo), "email address for the polite pool (env "+cli.EnvMailTo+")")
+	token := fs.String("api-key", os.Getenv(cli.EnvToken), "API key (env "+cli.EnvToken+")")
+	maxResults := fs.Int("max-results", mcp.DefaultMaxResults, "maximum number of results per tool call")
+	maxBytes := fs.Int("max-bytes", mcp.DefaultMaxBytes, "maximum size of a tool result in bytes")
+	rate := fs.Float64("rate", mcp.DefaultRateLimit, "tool calls allowed per second, negative to disable")
+	burst := fs.Int("burst", mcp.DefaultBurst, "tool calls allowed at once")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	opts := []core.Option{}
+	if *mailTo != "" {
+		opts = append(opts, core.PolitePool(*mailTo))
+	}
+	if *token != "" {
+		opts = append(opts, core.Auth(*token))
+	}
+	client := core.New(opts...)
+	if baseURL := os.Getenv(cli.EnvBaseURL); baseURL != "" {
+		client.BaseURL = baseURL
+	}
+	server := mcp.NewServer(client, mcp.Options{
+		Name:       "goalex",
+		MaxResults: *maxResults,
+		MaxBytes:   *maxBytes,
+		RateLimit:  *rate,
+		Burst:      *burst,
+	})
+
+	if *addr == "" {
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); !errors.Is(err, context.Canceled) {
+			return err
+		}
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", server)
+	srv := &http.Server{Addr: *addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	fmt.Fprintf(os.Stderr, "goalex-mcp: serving on http://%s/mcp\n", *addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Command goalex-proxy serves the OpenAlex REST API from a shared cache, so
+// that several services can use one API key and one rate limit.
+//
+// Usage:
+//
+//	goalex-proxy [flags]
+//
+// Point clients at the proxy instead of https://api.openalex.org and identify
+// them with the X-Goalex-Caller header. Usage per caller is served at
+// /_proxy/metrics.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/Sunhill666/goalex/internal/cli"
+	"github.com/Sunhill666/goalex/pkg/core"
+	"github.com/Sunhill666/goalex/pkg/proxy"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Args[1:])
+	stop()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "goalex-proxy: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("goalex-proxy", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8081", "address to listen on")
+	mailTo := fs.String("mailto", os.Getenv(cli.EnvMailTo), "email address for the polite pool (env "+cli.EnvMailTo+")")
+	token := fs.String("api-key", os.Getenv(cli.EnvToken), "API key (env "+cli.EnvToken+")")
+	ttl := fs.Duration("ttl", proxy.DefaultCacheTTL, "how long responses are cached")
+	size := fs.Int("cache-size", proxy.DefaultCacheSize, "maximum number of cached responses")
+	cacheBytes := fs.Int64("cache-bytes", proxy.DefaultCacheBytes, "maximum total size of cached responses in bytes")
+	entryBytes := fs.Int64("entry-bytes", proxy.DefaultEntryBytes, "size in bytes above which responses are not cached")
+	rate := fs.Float64("rate", proxy.DefaultRateLimit, "upstream requests allowed per second, negative to disable")
+	burst := fs.Int("burst", proxy.DefaultBurst, "upstream requests allowed at once")
+	callerHeader := fs.String("caller-header", proxy.DefaultCallerHeader, "request header identifying the caller")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return err
+	}
+
+	opts := []core.Option{}
+	if *mailTo != "" {
+		opts = append(opts, core.PolitePool(*mailTo))
+	}
+	if *token != "" {
+		opts = append(opts, core.Auth(*token))
+	}
+	if baseURL := os.Getenv(cli.EnvBaseURL); baseURL != "" {
+		opts = append(opts, core.WithBaseURL(baseURL))
+	}
+	p := proxy.New(proxy.Options{
+		CacheTTL:     *ttl,
+		CacheSize:    *size,
+		CacheBytes:   *cacheBytes,
+		EntryBytes:   *entryBytes,
+		RateLimit:    *rate,
+		Burst:        *burst,
+		CallerHeader: *callerHeader,
+	}, opts...)
+
+	srv := &http.Server{Addr: *addr, Handler: p, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	fmt.Fprintf(os.Stderr, "goalex-proxy: serving on http://%s\n", *addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
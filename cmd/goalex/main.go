@@ -0,0 +1,23 @@
+// Command goalex queries and exports OpenAlex data from the terminal.
+//
+// Usage:
+//
+//	goalex <command> <entity> [arguments] [flags]
+//
+// Run "goalex help" for the list of commands.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/Sunhill666/goalex/internal/cli"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr, os.Getenv)
+	stop()
+	os.Exit(code)
+}
@@ -19,6 +19,9 @@ var PolitePool = core.PolitePool
 // Auth configures the client to use the provided API token for authentication.
 var Auth = core.Auth
 
+// WithBaseURL configures the client to send requests to another base URL.
+var WithBaseURL = core.WithBaseURL
+
 // WithTimeout configures the client's timeout duration.
 var WithTimeout = core.WithTimeout
 
@@ -28,6 +31,55 @@ var WithRetry = core.WithRetry
 // WithHTTPClient configures the client to use a custom HTTP client.
 var WithHTTPClient = core.WithHTTPClient
 
+// WithLogger configures the client to log every request attempt to logger.
+var WithLogger = core.WithLogger
+
+// WithTracer configures the client to create a span for every builder operation.
+var WithTracer = core.WithTracer
+
+// WithMeter configures the client to record request metrics.
+var WithMeter = core.WithMeter
+
+// WithRateLimit configures the rate at which the client's batch helpers start requests.
+var WithRateLimit = core.WithRateLimit
+
+// WithCircuitBreaker configures the client to stop requests while OpenAlex is failing.
+var WithCircuitBreaker = core.WithCircuitBreaker
+
+// ErrCircuitOpen matches the error returned without contacting OpenAlex while the circuit breaker is open.
+var ErrCircuitOpen = core.ErrCircuitOpen
+
+// WithRequestCoalescing configures the client to share one round-trip between
+// concurrent requests for the same URL.
+var WithRequestCoalescing = core.WithRequestCoalescing
+
+// WithExtraFields configures the client to keep fields the models do not capture in Extra.
+var WithExtraFields = core.WithExtraFields
+
+// WithStrictDecoding configures the client to fail when a response has fields the models do not capture.
+var WithStrictDecoding = core.WithStrictDecoding
+
+// WithUnknownFieldHandler configures the client to report fields the models do not capture.
+var WithUnknownFieldHandler = core.WithUnknownFieldHandler
+
+// UnknownFields lists the fields of one decoded entity that its model does not capture.
+type UnknownFields = core.UnknownFields
+
+// UnknownFieldsError is returned in strict decoding mode when a response has unknown fields.
+type UnknownFieldsError = core.UnknownFieldsError
+
+// ErrNGramsUnavailable is returned when OpenAlex has no n-grams for a work.
+var ErrNGramsUnavailable = core.ErrNGramsUnavailable
+
+// WithMergeHandler configures the client to report single-entity requests answered with a merged entity.
+var WithMergeHandler = core.WithMergeHandler
+
+// Resolution reports how OpenAlex answered a request for a single entity.
+type Resolution = core.Resolution
+
+// WithMiddleware appends middleware that wraps every request attempt.
+var WithMiddleware = core.WithMiddleware
+
 // NewClient creates a new Client with the provided options.
 func NewClient(opts ...core.Option) *Client {
 	return core.New(opts...)
@@ -0,0 +1,433 @@
+// Package cli implements the goalex command-line tool.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+	"github.com/Sunhill666/goalex/pkg/export"
+)
+
+// Environment variables read by the tool.
+const (
+	EnvMailTo  = "GOALEX_MAILTO"
+	EnvToken   = "GOALEX_TOKEN"
+	EnvBaseURL = "GOALEX_BASE_URL"
+)
+
+const usage = `Usage: goalex <command> <entity> [arguments] [flags]
+
+Commands:
+  get <entity> <id>             fetch a single entity
+  list <entity>                 list one page of entities
+  search <entity> <query>       full-text search
+  count <entity>                print the number of matching entities
+  group <entity> <field>        count entities grouped by a field
+  autocomplete <entity> <text>  suggest entities matching a prefix
+  export <entity>               stream every matching entity with cursor pagination
+  schema-diff <entity> [file...]
+                                report response fields the models do not capture, in
+                                fixture files or in one live page of results
+
+Entities: works, authors, sources, institutions, topics, keywords, publishers, funders, concepts,
+          domains, fields, subfields, countries, continents, sdgs, languages, licenses,
+          work-types, source-types, institution-types
+
+Environment:
+  GOALEX_MAILTO   email address for the polite pool
+  GOALEX_TOKEN    API key
+  GOALEX_BASE_URL API base URL (defaults to https://api.openalex.org)
+
+Run "goalex <command> -h" for the flags of a command.
+`
+
+// options holds the parsed command line.
+type options struct {
+	command string
+	entity  string
+	args    []string
+	filters multiFlag
+	sorts   multiFlag
+	selects string
+	columns string
+	format  string
+	output  string
+	sample  int
+	seed    int
+	page    int
+	perPage int
+	limit   int
+	unknown bool
+	mailTo  string
+	token   string
+	baseURL string
+	stdout  io.Writer
+	stderr  io.Writer
+	drift   driftReport
+}
+
+// multiFlag collects a repeatable string flag.
+type multiFlag []string
+
+func (m *multiFlag) String() string {
+	return strings.Join(*m, ",")
+}
+
+func (m *multiFlag) Set(v string) error {
+	*m = append(*m, v)
+	return nil
+}
+
+// Run executes the tool with the given arguments, excluding the program name,
+// and returns the process exit code.
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer, getenv func(string) string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		_, _ = fmt.Fprint(stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	o := &options{
+		command: args[0],
+		stdout:  stdout,
+		stderr:  stderr,
+		mailTo:  getenv(EnvMailTo),
+		token:   getenv(EnvToken),
+		baseURL: getenv(EnvBaseURL),
+	}
+	if err := o.parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		_, _ = fmt.Fprintf(stderr, "goalex: %v\n", err)
+		return 2
+	}
+
+	opts := []core.Option{}
+	if o.mailTo != "" {
+		opts = append(opts, core.PolitePool(o.mailTo))
+	}
+	if o.token != "" {
+		opts = append(opts, core.Auth(o.token))
+	}
+	if o.command == "schema-diff" {
+		opts = append(opts, core.WithUnknownFieldHandler(o.drift.add))
+	}
+	client := core.New(opts...)
+	if o.baseURL != "" {
+		client.BaseURL = o.baseURL
+	}
+
+	if err := dispatch(ctx, client, o); err != nil {
+		_, _ = fmt.Fprintf(stderr, "goalex: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// positionals is the number of arguments each command expects after the
+// entity, or -1 for any number.
+var positionals = map[string]int{
+	"get":          1,
+	"list":         0,
+	"search":       1,
+	"count":        0,
+	"group":        1,
+	"autocomplete": 1,
+	"export":       0,
+	"schema-diff":  -1,
+}
+
+func (o *options) parse(args []string) error {
+	want, ok := positionals[o.command]
+	if !ok {
+		return fmt.Errorf("unknown command %q", o.command)
+	}
+
+	fs := flag.NewFlagSet(o.command, flag.ContinueOnError)
+	fs.SetOutput(o.stderr)
+	fs.Var(&o.filters, "filter", "filter as field:value, repeatable")
+	fs.Var(&o.sorts, "sort", "sort field, with :desc for descending order, repeatable")
+	fs.StringVar(&o.selects, "select", "", "comma-separated fields to return")
+	fs.StringVar(&o.columns, "columns", "", "comma-separated output columns as dotted paths, optionally name=path")
+	fs.StringVar(&o.format, "format", "", "output format: table, json, jsonl, csv or tsv")
+	fs.StringVar(&o.output, "output", "", "write output to a file instead of stdout")
+	fs.IntVar(&o.sample, "sample", 0, "return a random sample of this size")
+	fs.IntVar(&o.seed, "seed", 0, "seed for reproducible samples")
+	fs.IntVar(&o.page, "page", 0, "page number")
+	fs.IntVar(&o.perPage, "per-page", 0, "results per page (max 200)")
+	fs.IntVar(&o.limit, "limit", 0, "stop an export after this many records")
+	fs.BoolVar(&o.unknown, "include-unknown", false, "include the unknown group when grouping")
+	fs.StringVar(&o.mailTo, "mailto", o.mailTo, "email address for the polite pool (env "+EnvMailTo+")")
+	fs.StringVar(&o.token, "api-key", o.token, "API key (env "+EnvToken+")")
+
+	// Allow flags before, between and after positional arguments.
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	if len(positional) == 0 {
+		return fmt.Errorf("%s: missing entity", o.command)
+	}
+	o.entity, o.args = positional[0], positional[1:]
+	if want >= 0 && len(o.args) != want {
+		return fmt.Errorf("%s: expected %d argument(s) after the entity, got %d", o.command, want, len(o.args))
+	}
+	if o.format == "" {
+		o.format = "table"
+		switch o.command {
+		case "get":
+			o.format = "json"
+		case "export":
+			o.format = "jsonl"
+		}
+	}
+	return nil
+}
+
+func dispatch(ctx context.Context, c *core.Client, o *options) error {
+	switch strings.ToLower(o.entity) {
+	case "works":
+		return run(ctx, c.Works(), o)
+	case "authors":
+		return run(ctx, c.Authors(), o)
+	case "sources":
+		return run(ctx, c.Sources(), o)
+	case "institutions":
+		return run(ctx, c.Institutions(), o)
+	case "topics":
+		return run(ctx, c.Topics(), o)
+	case "keywords":
+		return run(ctx, c.Keywords(), o)
+	case "publishers":
+		return run(ctx, c.Publishers(), o)
+	case "funders":
+		return run(ctx, c.Funders(), o)
+	case "concepts":
+		return run(ctx, c.Concepts(), o)
+	case "domains":
+		return run(ctx, c.Domains(), o)
+	case "fields":
+		return run(ctx, c.Fields(), o)
+	case "subfields":
+		return run(ctx, c.Subfields(), o)
+	case "countries":
+		return run(ctx, c.Countries(), o)
+	case "continents":
+		return run(ctx, c.Continents(), o)
+	case "sdgs":
+		return run(ctx, c.SDGs(), o)
+	case "languages":
+		return run(ctx, c.Languages(), o)
+	case "licenses":
+		return run(ctx, c.Licenses(), o)
+	case "work-types":
+		return run(ctx, c.WorkTypes(), o)
+	case "source-types":
+		return run(ctx, c.SourceTypes(), o)
+	case "institution-types":
+		return run(ctx, c.InstitutionTypes(), o)
+	default:
+		return fmt.Errorf("unknown entity %q", o.entity)
+	}
+}
+
+// apply copies the query flags onto the builder.
+func apply[T any](q *core.QueryBuilder[T], o *options) error {
+	for _, f := range o.filters {
+		field, value, ok := strings.Cut(f, ":")
+		if !ok {
+			return fmt.Errorf("invalid filter %q, expected field:value", f)
+		}
+		q.Filter(field, value)
+	}
+	for _, s := range o.sorts {
+		for part := range strings.SplitSeq(s, ",") {
+			field, dir, _ := strings.Cut(part, ":")
+			q.Sort(field, dir == "desc")
+		}
+	}
+	if o.selects != "" {
+		q.Select(strings.Split(o.selects, ",")...)
+	}
+	q.Sample(o.sample)
+	if o.seed != 0 {
+		q.Seed(o.seed)
+	}
+	if o.page > 0 {
+		q.Page(o.page)
+	}
+	if o.perPage > 0 {
+		q.PerPage(o.perPage)
+	}
+	return nil
+}
+
+func run[T any](ctx context.Context, q *core.QueryBuilder[T], o *options) error {
+	if err := apply(q, o); err != nil {
+		return err
+	}
+
+	if o.output == "" {
+		return runCommand(ctx, q, o, o.stdout)
+	}
+	// The output is written to a temporary file that replaces o.output only
+	// once the command succeeds, so a failed command leaves it untouched.
+	f, err := os.CreateTemp(filepath.Dir(o.output), "."+filepath.Base(o.output)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	if err := runCommand(ctx, q, o, f); err != nil {
+		return err
+	}
+	if err := f.Chmod(0o644); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), o.output)
+}
+
+func runCommand[T any](ctx context.Context, q *core.QueryBuilder[T], o *options, out io.Writer) error {
+	switch o.command {
+	case "get":
+		entity, err := q.GetWithContext(ctx, o.args[0])
+		if err != nil {
+			return err
+		}
+		return write(out, o, []*T{entity}, "id,display_name")
+	case "list":
+		resp, err := q.ListWithMetaContext(ctx)
+		if err != nil {
+			return err
+		}
+		return write(out, o, resp.Results, "id,display_name")
+	case "search":
+		resp, err := q.Search(o.args[0]).ListWithMetaContext(ctx)
+		if err != nil {
+			return err
+		}
+		return write(out, o, resp.Results, "id,display_name")
+	case "count":
+		resp, err := q.PerPage(1).Select("id").ListWithMetaContext(ctx)
+		if err != nil {
+			return err
+		}
+		count := 0
+		if resp.Meta != nil {
+			count = resp.Meta.Count
+		}
+		_, err = fmt.Fprintln(out, count)
+		return err
+	case "group":
+		groups, err := q.GroupBy(o.args[0], o.unknown).ListGroupByWithContext(ctx)
+		if err != nil {
+			return err
+		}
+		return write(out, o, groups, "key,key_display_name,count")
+	case "autocomplete":
+		resp, err := q.AutoComplete(o.args[0]).ListWithMetaContext(ctx)
+		if err != nil {
+			return err
+		}
+		return write(out, o, resp.Results, "id,display_name,entity_type,hint")
+	case "export":
+		format, err := export.ParseFormat(o.format)
+		if err != nil {
+			return err
+		}
+		columns, err := parseColumns(o, "")
+		if err != nil {
+			return err
+		}
+		n, err := export.Query(ctx, q, out, export.Options{
+			Format:  format,
+			Columns: columns,
+			PerPage: o.perPage,
+			Limit:   o.limit,
+		})
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(o.stderr, "exported %d records\n", n)
+		return nil
+	case "schema-diff":
+		return schemaDiff(ctx, q, o, out)
+	}
+	return fmt.Errorf("unknown command %q", o.command)
+}
+
+func parseColumns(o *options, defaults string) ([]export.Column, error) {
+	spec := o.columns
+	if spec == "" {
+		spec = defaults
+	}
+	if spec == "" {
+		return nil, nil
+	}
+	return export.ParseColumns(strings.Split(spec, ",")...)
+}
+
+// write renders records in the requested format. JSON prints a single value
+// for get and an array otherwise; the other formats go through export.Writer.
+func write[T any](out io.Writer, o *options, records []*T, defaultColumns string) error {
+	if o.format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if o.command == "get" {
+			return enc.Encode(records[0])
+		}
+		if records == nil {
+			records = []*T{}
+		}
+		return enc.Encode(records)
+	}
+
+	format, err := export.ParseFormat(o.format)
+	if err != nil {
+		return err
+	}
+	defaults := defaultColumns
+	if format == export.FormatJSONL {
+		defaults = ""
+	}
+	columns, err := parseColumns(o, defaults)
+	if err != nil {
+		return err
+	}
+	w, err := export.NewWriter(out, format, columns)
+	if err != nil {
+		return err
+	}
+	for _, r := range records {
+		if err := w.Write(r); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
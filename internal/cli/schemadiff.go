@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// errDrift is returned by schema-diff when it finds fields the models do not
+// capture, so that scripts can fail on schema changes.
+var errDrift = errors.New("schema-diff: found fields the models do not capture")
+
+// driftReport counts, per entity type, the records carrying each unknown field.
+type driftReport struct {
+	records int
+	fields  map[string]map[string]int
+}
+
+func (r *driftReport) add(u core.UnknownFields) {
+	if r.fields == nil {
+		r.fields = make(map[string]map[string]int)
+	}
+	if r.fields[u.Entity] == nil {
+		r.fields[u.Entity] = make(map[string]int)
+	}
+	for _, f := range u.Fields {
+		r.fields[u.Entity][f]++
+	}
+}
+
+// write prints the unknown fields of each entity type, most frequent first.
+func (r *driftReport) write(out io.Writer) error {
+	if len(r.fields) == 0 {
+		_, err := fmt.Fprintf(out, "no unknown fields in %d records\n", r.records)
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	for _, entity := range slices.Sorted(maps.Keys(r.fields)) {
+		counts := r.fields[entity]
+		_, _ = fmt.Fprintf(tw, "%s: %d unknown fields in %d records\n", entity, len(counts), r.records)
+		fields := slices.Sorted(maps.Keys(counts))
+		slices.SortStableFunc(fields, func(a, b string) int { return counts[b] - counts[a] })
+		for _, f := range fields {
+			_, _ = fmt.Fprintf(tw, "  %s\t%d\n", f, counts[f])
+		}
+	}
+	return tw.Flush()
+}
+
+// schemaDiff compares fixture files, or one live page of results when no file
+// is given, against the model of the entity.
+func schemaDiff[T any](ctx context.Context, q *core.QueryBuilder[T], o *options, out io.Writer) error {
+	if len(o.args) == 0 {
+		resp, err := q.ListWithMetaContext(ctx)
+		if err != nil {
+			return err
+		}
+		o.drift.records += len(resp.Results)
+	}
+	for _, name := range o.args {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		records, err := fixtureRecords(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		for _, record := range records {
+			unknown, err := core.DiffSchema(record, new(T))
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			for _, u := range unknown {
+				o.drift.add(u)
+			}
+		}
+		o.drift.records += len(records)
+	}
+	if err := o.drift.write(out); err != nil {
+		return err
+	}
+	if len(o.drift.fields) > 0 {
+		return errDrift
+	}
+	return nil
+}
+
+// fixtureRecords splits a fixture into entity records. A fixture is a single
+// entity, an array of entities, a list response or JSON Lines.
+func fixtureRecords(data []byte) ([]json.RawMessage, error) {
+	data = bytes.TrimSpace(data)
+	if json.Valid(data) {
+		switch {
+		case bytes.HasPrefix(data, []byte("[")):
+			var records []json.RawMessage
+			err := json.Unmarshal(data, &records)
+			return records, err
+		case bytes.HasPrefix(data, []byte("{")):
+			var list struct {
+				Results []json.RawMessage `json:"results"`
+			}
+			if err := json.Unmarshal(data, &list); err == nil && list.Results != nil {
+				return list.Results, nil
+			}
+			return []json.RawMessage{data}, nil
+		}
+	}
+	var records []json.RawMessage
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !json.Valid([]byte(line)) {
+			return nil, fmt.Errorf("line %d is not valid JSON", i+1)
+		}
+		records = append(records, json.RawMessage(line))
+	}
+	return records, nil
+}
@@ -1,5 +1,7 @@
 package model
 
+import "encoding/json"
+
 type Author struct {
 	DehydratedAuthor
 	Affiliations            []*AuthorAffiliation          `json:"affiliations,omitempty"`
@@ -16,6 +18,9 @@ type Author struct {
 	WorksAPIURL             string                        `json:"works_api_url,omitempty"`
 	WorksCount              int                           `json:"works_count,omitempty"`
 	XConcepts               []*DehydratedConceptWithScore `json:"x_concepts,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type AuthorAffiliation struct {
@@ -1,5 +1,7 @@
 package model
 
+import "encoding/json"
+
 type Concept struct {
 	Ancestors         []*DehydratedConcept          `json:"ancestors,omitempty"`
 	CitedByCount      int                           `json:"cited_by_count,omitempty"`
@@ -17,6 +19,9 @@ type Concept struct {
 	Wikidata          string                        `json:"wikidata,omitempty"`
 	WorksAPIURL       string                        `json:"works_api_url,omitempty"`
 	WorksCount        int                           `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type ConceptIDs struct {
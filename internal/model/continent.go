@@ -0,0 +1,31 @@
+package model
+
+import "encoding/json"
+
+type Continent struct {
+	CitedByCount            int                  `json:"cited_by_count,omitempty"`
+	Countries               []*DehydratedCountry `json:"countries,omitempty"`
+	CreatedDate             string               `json:"created_date,omitempty"`
+	Description             string               `json:"description,omitempty"`
+	DisplayName             string               `json:"display_name,omitempty"`
+	DisplayNameAlternatives []string             `json:"display_name_alternatives,omitempty"`
+	ID                      string               `json:"id,omitempty"`
+	IDs                     *ContinentIDs        `json:"ids,omitempty"`
+	UpdatedDate             string               `json:"updated_date,omitempty"`
+	WorksAPIURL             string               `json:"works_api_url,omitempty"`
+	WorksCount              int                  `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type ContinentIDs struct {
+	OpenAlex  string `json:"openalex,omitempty"`
+	Wikidata  string `json:"wikidata,omitempty"`
+	Wikipedia string `json:"wikipedia,omitempty"`
+}
+
+type DehydratedContinent struct {
+	DisplayName string `json:"display_name,omitempty"`
+	ID          string `json:"id,omitempty"`
+}
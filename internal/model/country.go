@@ -0,0 +1,36 @@
+package model
+
+import "encoding/json"
+
+type Country struct {
+	AuthorsAPIURL           string               `json:"authors_api_url,omitempty"`
+	CitedByCount            int                  `json:"cited_by_count,omitempty"`
+	Continent               *DehydratedContinent `json:"continent,omitempty"`
+	CountryCode             string               `json:"country_code,omitempty"`
+	CreatedDate             string               `json:"created_date,omitempty"`
+	Description             string               `json:"description,omitempty"`
+	DisplayName             string               `json:"display_name,omitempty"`
+	DisplayNameAlternatives []string             `json:"display_name_alternatives,omitempty"`
+	ID                      string               `json:"id,omitempty"`
+	IDs                     *CountryIDs          `json:"ids,omitempty"`
+	InstitutionsAPIURL      string               `json:"institutions_api_url,omitempty"`
+	IsGlobalSouth           bool                 `json:"is_global_south,omitempty"`
+	UpdatedDate             string               `json:"updated_date,omitempty"`
+	WorksAPIURL             string               `json:"works_api_url,omitempty"`
+	WorksCount              int                  `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type CountryIDs struct {
+	ISO       string `json:"iso,omitempty"`
+	OpenAlex  string `json:"openalex,omitempty"`
+	Wikidata  string `json:"wikidata,omitempty"`
+	Wikipedia string `json:"wikipedia,omitempty"`
+}
+
+type DehydratedCountry struct {
+	DisplayName string `json:"display_name,omitempty"`
+	ID          string `json:"id,omitempty"`
+}
@@ -0,0 +1,193 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+)
+
+// The entity models encode the fields kept in Extra along with their own, so
+// that entities written back out as JSON keep the data the models do not
+// capture. Each method converts the model to a type without methods to encode
+// its own fields.
+
+func (a Author) MarshalJSON() ([]byte, error) {
+	type plain Author
+	return withExtra(plain(a), a.Extra)
+}
+
+func (c Concept) MarshalJSON() ([]byte, error) {
+	type plain Concept
+	return withExtra(plain(c), c.Extra)
+}
+
+func (c Continent) MarshalJSON() ([]byte, error) {
+	type plain Continent
+	return withExtra(plain(c), c.Extra)
+}
+
+func (c Country) MarshalJSON() ([]byte, error) {
+	type plain Country
+	return withExtra(plain(c), c.Extra)
+}
+
+func (d Domain) MarshalJSON() ([]byte, error) {
+	type plain Domain
+	return withExtra(plain(d), d.Extra)
+}
+
+func (f Field) MarshalJSON() ([]byte, error) {
+	type plain Field
+	return withExtra(plain(f), f.Extra)
+}
+
+func (f Funder) MarshalJSON() ([]byte, error) {
+	type plain Funder
+	return withExtra(plain(f), f.Extra)
+}
+
+func (i Institution) MarshalJSON() ([]byte, error) {
+	type plain Institution
+	return withExtra(plain(i), i.Extra)
+}
+
+func (k Keyword) MarshalJSON() ([]byte, error) {
+	type plain Keyword
+	return withExtra(plain(k), k.Extra)
+}
+
+func (l Language) MarshalJSON() ([]byte, error) {
+	type plain Language
+	return withExtra(plain(l), l.Extra)
+}
+
+func (l License) MarshalJSON() ([]byte, error) {
+	type plain License
+	return withExtra(plain(l), l.Extra)
+}
+
+func (p Publisher) MarshalJSON() ([]byte, error) {
+	type plain Publisher
+	return withExtra(plain(p), p.Extra)
+}
+
+func (s SDG) MarshalJSON() ([]byte, error) {
+	type plain SDG
+	return withExtra(plain(s), s.Extra)
+}
+
+func (s Source) MarshalJSON() ([]byte, error) {
+	type plain Source
+	return withExtra(plain(s), s.Extra)
+}
+
+func (s Subfield) MarshalJSON() ([]byte, error) {
+	type plain Subfield
+	return withExtra(plain(s), s.Extra)
+}
+
+func (t Topic) MarshalJSON() ([]byte, error) {
+	type plain Topic
+	return withExtra(plain(t), t.Extra)
+}
+
+func (w Work) MarshalJSON() ([]byte, error) {
+	type plain Work
+	return withExtra(plain(w), w.Extra)
+}
+
+func (t WorkType) MarshalJSON() ([]byte, error) {
+	type plain WorkType
+	return withExtra(plain(t), t.Extra)
+}
+
+func (t SourceType) MarshalJSON() ([]byte, error) {
+	type plain SourceType
+	return withExtra(plain(t), t.Extra)
+}
+
+func (t InstitutionType) MarshalJSON() ([]byte, error) {
+	type plain InstitutionType
+	return withExtra(plain(t), t.Extra)
+}
+
+// The types embedding Topic would otherwise be encoded by the promoted
+// Topic.MarshalJSON, without their own fields.
+
+func (t TopicWithCount) MarshalJSON() ([]byte, error) {
+	return joinObjects(t.Topic, struct {
+		Count int `json:"count,omitempty"`
+	}{t.Count})
+}
+
+func (t TopicWithScore) MarshalJSON() ([]byte, error) {
+	return joinObjects(t.Topic, struct {
+		Score float32 `json:"score,omitempty"`
+	}{t.Score})
+}
+
+func (t TopicShare) MarshalJSON() ([]byte, error) {
+	return joinObjects(t.Topic, struct {
+		Value float64 `json:"value,omitempty"`
+	}{t.Value})
+}
+
+// withExtra encodes v followed by the members of extra, sorted by name, that
+// v does not already have.
+func withExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+	var known map[string]json.RawMessage
+	if err := json.Unmarshal(b, &known); err != nil {
+		return nil, err
+	}
+	var members bytes.Buffer
+	members.WriteByte('{')
+	for _, name := range slices.Sorted(maps.Keys(extra)) {
+		if _, ok := known[name]; ok {
+			continue
+		}
+		if members.Len() > 1 {
+			members.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		members.Write(key)
+		members.WriteByte(':')
+		members.Write(extra[name])
+	}
+	members.WriteByte('}')
+	return join(b, members.Bytes()), nil
+}
+
+// joinObjects encodes values that encode as JSON objects into one object.
+func joinObjects(values ...any) ([]byte, error) {
+	var joined []byte
+	for _, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		if joined == nil {
+			joined = b
+		} else {
+			joined = join(joined, b)
+		}
+	}
+	return joined, nil
+}
+
+// join appends the members of JSON object b to those of JSON object a.
+func join(a, b []byte) []byte {
+	a, b = bytes.TrimSpace(a), bytes.TrimSpace(b)
+	if len(b) <= 2 {
+		return a
+	}
+	if len(a) <= 2 {
+		return b
+	}
+	joined := append(slices.Clip(a[:len(a)-1]), ',')
+	return append(joined, b[1:]...)
+}
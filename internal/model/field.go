@@ -0,0 +1,22 @@
+package model
+
+import "encoding/json"
+
+type Field struct {
+	CitedByCount            int            `json:"cited_by_count,omitempty"`
+	CreatedDate             string         `json:"created_date,omitempty"`
+	Description             string         `json:"description,omitempty"`
+	DisplayName             string         `json:"display_name,omitempty"`
+	DisplayNameAlternatives []string       `json:"display_name_alternatives,omitempty"`
+	Domain                  *TopicField    `json:"domain,omitempty"`
+	ID                      string         `json:"id,omitempty"`
+	IDs                     *TopicLevelIDs `json:"ids,omitempty"`
+	Siblings                []*TopicField  `json:"siblings,omitempty"`
+	Subfields               []*TopicField  `json:"subfields,omitempty"`
+	UpdatedDate             string         `json:"updated_date,omitempty"`
+	WorksAPIURL             string         `json:"works_api_url,omitempty"`
+	WorksCount              int            `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "encoding/json"
+
 type Funder struct {
 	AlternateTitles   []string        `json:"alternate_titles,omitempty"`
 	CitedByCount      int             `json:"cited_by_count,omitempty"`
@@ -18,6 +20,9 @@ type Funder struct {
 	SummaryStats      *SummaryStats   `json:"summary_stats,omitempty"`
 	UpdatedDate       string          `json:"updated_date,omitempty"`
 	WorksCount        int             `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type FunderIDs struct {
@@ -1,5 +1,7 @@
 package model
 
+import "encoding/json"
+
 type Institution struct {
 	DehydratedInstitution
 	AssociatedInstitutions  []*DehydratedInstitutionWithRelationship `json:"associated_institutions,omitempty"`
@@ -24,6 +26,9 @@ type Institution struct {
 	WorksAPIURL             string                                   `json:"works_api_url,omitempty"`
 	WorksCount              int                                      `json:"works_count,omitempty"`
 	XConcepts               []*DehydratedConceptWithScore            `json:"x_concepts,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type InstitutionIDs struct {
@@ -1,11 +1,16 @@
 package model
 
+import "encoding/json"
+
 type Keyword struct {
 	DehydratedKeyword
 	CitedByCount int    `json:"cited_by_count,omitempty"`
 	CreatedDate  string `json:"created_date,omitempty"`
 	UpdatedDate  string `json:"updated_date,omitempty"`
 	WorksCount   int    `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type DehydratedKeyword struct {
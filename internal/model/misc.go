@@ -51,6 +51,11 @@ type GroupBy struct {
 	Count          int    `json:"count,omitempty"`
 }
 
+type GroupByResult struct {
+	Field  string     `json:"group_by_key,omitempty"`
+	Groups []*GroupBy `json:"groups,omitempty"`
+}
+
 type PaginatedResponseMeta struct {
 	Count       int    `json:"count,omitempty"`
 	DBRespTime  int    `json:"db_response_time_ms,omitempty"`
@@ -0,0 +1,19 @@
+package model
+
+type NGram struct {
+	Count         int     `json:"ngram_count,omitempty"`
+	TermFrequency float64 `json:"term_frequency,omitempty"`
+	Text          string  `json:"ngram,omitempty"`
+	Tokens        int     `json:"ngram_tokens,omitempty"`
+}
+
+type NGramsMeta struct {
+	Count      int    `json:"count,omitempty"`
+	DOI        string `json:"doi,omitempty"`
+	OpenAlexID string `json:"openalex_id,omitempty"`
+}
+
+type NGramsResponse struct {
+	Meta   *NGramsMeta `json:"meta,omitempty"`
+	NGrams []*NGram    `json:"ngrams,omitempty"`
+}
@@ -1,7 +1,8 @@
 package model
 
 type PaginatedResponse[T any] struct {
-	Meta    *PaginatedResponseMeta `json:"meta,omitempty"`
-	Results []*T                   `json:"results,omitempty"`
-	GroupBy []*GroupBy             `json:"group_by,omitempty"`
+	Meta     *PaginatedResponseMeta `json:"meta,omitempty"`
+	Results  []*T                   `json:"results,omitempty"`
+	GroupBy  []*GroupBy             `json:"group_by,omitempty"`
+	GroupBys []*GroupByResult       `json:"group_bys,omitempty"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import "encoding/json"
+
+type SDG struct {
+	CitedByCount      int     `json:"cited_by_count,omitempty"`
+	CreatedDate       string  `json:"created_date,omitempty"`
+	Description       string  `json:"description,omitempty"`
+	DisplayName       string  `json:"display_name,omitempty"`
+	ID                string  `json:"id,omitempty"`
+	IDs               *SDGIDs `json:"ids,omitempty"`
+	ImageThumbnailURL string  `json:"image_thumbnail_url,omitempty"`
+	ImageURL          string  `json:"image_url,omitempty"`
+	UpdatedDate       string  `json:"updated_date,omitempty"`
+	WorksAPIURL       string  `json:"works_api_url,omitempty"`
+	WorksCount        int     `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type SDGIDs struct {
+	OpenAlex  string `json:"openalex,omitempty"`
+	UN        string `json:"un,omitempty"`
+	Wikidata  string `json:"wikidata,omitempty"`
+	Wikipedia string `json:"wikipedia,omitempty"`
+}
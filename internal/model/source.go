@@ -1,5 +1,7 @@
 package model
 
+import "encoding/json"
+
 type Source struct {
 	DehydratedSource
 	AbbreviatedTitle string                        `json:"abbreviated_title,omitempty"`
@@ -20,6 +22,9 @@ type Source struct {
 	WorksAPIURL      string                        `json:"works_api_url,omitempty"`
 	WorksCount       int                           `json:"works_count,omitempty"`
 	XConcepts        []*DehydratedConceptWithScore `json:"x_concepts,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type SourceIDs struct {
@@ -0,0 +1,15 @@
+package model
+
+type TextClassification struct {
+	Concepts     []*DehydratedConceptWithScore `json:"concepts,omitempty"`
+	Keywords     []*DehydratedKeyword          `json:"keywords,omitempty"`
+	Meta         *TextClassificationMeta       `json:"meta,omitempty"`
+	PrimaryTopic *TopicWithScore               `json:"primary_topic,omitempty"`
+	Topics       []*TopicWithScore             `json:"topics,omitempty"`
+}
+
+type TextClassificationMeta struct {
+	ConceptsCount int `json:"concepts_count,omitempty"`
+	KeywordsCount int `json:"keywords_count,omitempty"`
+	TopicsCount   int `json:"topics_count,omitempty"`
+}
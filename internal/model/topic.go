@@ -1,5 +1,7 @@
 package model
 
+import "encoding/json"
+
 type Topic struct {
 	Description string      `json:"description,omitempty"`
 	DisplayName string      `json:"display_name,omitempty"`
@@ -11,6 +13,9 @@ type Topic struct {
 	Subfield    *TopicField `json:"subfield,omitempty"`
 	UpdateDate  string      `json:"update_date,omitempty"`
 	WorksCount  int         `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type TopicIDs struct {
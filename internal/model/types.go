@@ -0,0 +1,46 @@
+package model
+
+import "encoding/json"
+
+type WorkType struct {
+	CitedByCount  int      `json:"cited_by_count,omitempty"`
+	CreatedDate   string   `json:"created_date,omitempty"`
+	CrossrefTypes []string `json:"crossref_types,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	DisplayName   string   `json:"display_name,omitempty"`
+	ID            string   `json:"id,omitempty"`
+	UpdatedDate   string   `json:"updated_date,omitempty"`
+	WorksAPIURL   string   `json:"works_api_url,omitempty"`
+	WorksCount    int      `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type SourceType struct {
+	CitedByCount int    `json:"cited_by_count,omitempty"`
+	CreatedDate  string `json:"created_date,omitempty"`
+	Description  string `json:"description,omitempty"`
+	DisplayName  string `json:"display_name,omitempty"`
+	ID           string `json:"id,omitempty"`
+	UpdatedDate  string `json:"updated_date,omitempty"`
+	WorksAPIURL  string `json:"works_api_url,omitempty"`
+	WorksCount   int    `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type InstitutionType struct {
+	CitedByCount int    `json:"cited_by_count,omitempty"`
+	CreatedDate  string `json:"created_date,omitempty"`
+	Description  string `json:"description,omitempty"`
+	DisplayName  string `json:"display_name,omitempty"`
+	ID           string `json:"id,omitempty"`
+	UpdatedDate  string `json:"updated_date,omitempty"`
+	WorksAPIURL  string `json:"works_api_url,omitempty"`
+	WorksCount   int    `json:"works_count,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
+}
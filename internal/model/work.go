@@ -1,5 +1,7 @@
 package model
 
+import "encoding/json"
+
 type Affiliation struct {
 	InstitutionIDs       []string `json:"institution_ids,omitempty"`
 	RawAffiliationString string   `json:"raw_affiliation_string,omitempty"`
@@ -134,4 +136,7 @@ type Work struct {
 	Type                         string                        `json:"type,omitempty"`
 	TypeCrossref                 string                        `json:"type_crossref,omitempty"`
 	UpdatedDate                  string                        `json:"updated_date,omitempty"`
+
+	// Extra holds the fields of the response that the model does not capture.
+	Extra map[string]json.RawMessage `json:"-"`
 }
@@ -0,0 +1,232 @@
+// Package bibliometrics computes bibliometric indicators over arbitrary sets
+// of works, such as a department's works of 2018 to 2022.
+//
+// OpenAlex precomputes the h-index, i10-index and 2-year mean citedness of
+// whole authors, sources and institutions only. This package computes them,
+// together with the g-index, the m-quotient, field-normalized citation
+// averages and top-percentile shares, from the works returned by a query or,
+// with package storeutil, kept in a local store.
+package bibliometrics
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// Fields are the work fields the indicators are computed from. FromQuery
+// selects only these.
+var Fields = []string{"id", "publication_year", "cited_by_count", "counts_by_year", "fwci", "citation_normalized_percentile"}
+
+// Options configures the computation of indicators.
+type Options struct {
+	// Year is the year the indicators are computed for, which sets the career
+	// length of the m-quotient and the citation window of the 2-year mean
+	// citedness. Defaults to the current year.
+	Year int
+}
+
+// Indicators are the bibliometric indicators of a set of works.
+type Indicators struct {
+	// Works is the number of distinct works.
+	Works int
+	// Citations is the total number of citations of the works.
+	Citations     int
+	MeanCitations float64
+	// HIndex is the largest h such that h works have at least h citations each.
+	HIndex int
+	// GIndex is the largest g such that the g most cited works have at least
+	// g² citations together.
+	GIndex int
+	// I10Index is the number of works with at least 10 citations.
+	I10Index int
+	// FirstYear and LastYear are the earliest and latest publication years.
+	FirstYear, LastYear int
+	// MQuotient is the h-index divided by the number of years from FirstYear
+	// to Options.Year inclusive.
+	MQuotient float64
+	// TwoYearMeanCitedness is the mean number of citations received in the
+	// year before Options.Year by the works published in the two years before
+	// that, as OpenAlex defines it for whole entities.
+	TwoYearMeanCitedness float64
+
+	// FWCIWorks is the number of works with a field-weighted citation impact,
+	// which MeanFWCI averages over. A MeanFWCI of 1 is the world average.
+	FWCIWorks int
+	MeanFWCI  float64
+	// PercentileWorks is the number of works with a citation-normalized
+	// percentile, which the remaining indicators are relative to.
+	PercentileWorks int
+	// MeanPercentile is the mean citation-normalized percentile, from 0 to 1.
+	MeanPercentile float64
+	// Top1Share and Top10Share are the shares of works among the 1% and 10%
+	// most cited works of their field and year.
+	Top1Share  float64
+	Top10Share float64
+}
+
+// Compute returns the indicators of works. Works that appear more than once,
+// by ID, are counted once.
+func Compute(works []*model.Work, opts Options) *Indicators {
+	if opts.Year == 0 {
+		opts.Year = time.Now().Year()
+	}
+	works = distinct(works)
+	ind := &Indicators{Works: len(works)}
+
+	citations := make([]int, len(works))
+	var fwci, percentile float64
+	var top1, top10, recent, recentCitations int
+	for i, w := range works {
+		citations[i] = w.CitedByCount
+		ind.Citations += w.CitedByCount
+		if w.CitedByCount >= 10 {
+			ind.I10Index++
+		}
+		if y := w.PublicationYear; y > 0 {
+			if ind.FirstYear == 0 || y < ind.FirstYear {
+				ind.FirstYear = y
+			}
+			ind.LastYear = max(ind.LastYear, y)
+		}
+		if w.PublicationYear == opts.Year-2 || w.PublicationYear == opts.Year-3 {
+			recent++
+			recentCitations += citationsIn(w, opts.Year-1)
+		}
+		// OpenAlex sends null for works it cannot normalize. A zero fwci
+		// decodes the same as null, so it counts when the percentile, which
+		// is normalized alongside it, is present.
+		p := w.CitationNormalizedPercentile
+		if w.FWCI > 0 || p != nil {
+			ind.FWCIWorks++
+			fwci += float64(w.FWCI)
+		}
+		if p != nil {
+			ind.PercentileWorks++
+			percentile += p.Value
+			if p.IsTop1Percent {
+				top1++
+			}
+			if p.IsTop10Percent {
+				top10++
+			}
+		}
+	}
+
+	ind.HIndex = HIndex(citations)
+	ind.GIndex = GIndex(citations)
+	if ind.Works > 0 {
+		ind.MeanCitations = float64(ind.Citations) / float64(ind.Works)
+	}
+	if ind.FirstYear > 0 && opts.Year >= ind.FirstYear {
+		ind.MQuotient = float64(ind.HIndex) / float64(opts.Year-ind.FirstYear+1)
+	}
+	if recent > 0 {
+		ind.TwoYearMeanCitedness = float64(recentCitations) / float64(recent)
+	}
+	if ind.FWCIWorks > 0 {
+		ind.MeanFWCI = fwci / float64(ind.FWCIWorks)
+	}
+	if ind.PercentileWorks > 0 {
+		n := float64(ind.PercentileWorks)
+		ind.MeanPercentile = percentile / n
+		ind.Top1Share = float64(top1) / n
+		ind.Top10Share = float64(top10) / n
+	}
+	return ind
+}
+
+// FromQuery computes the indicators of every work of a works query, paging
+// through the results with a cursor and selecting Fields besides the fields q
+// already selects. q itself is not changed:
+//
+//	q := client.Works().
+//		Filter("authorships.institutions.id", "I136199984").
+//		Filter("publication_year", "2018-2022")
+//	ind, err := bibliometrics.FromQuery(ctx, q, bibliometrics.Options{})
+func FromQuery(ctx context.Context, q *core.QueryBuilder[model.Work], opts Options) (*Indicators, error) {
+	q = q.Clone().PerPage(200)
+	selected := q.Selected()
+	for _, field := range Fields {
+		if !slices.Contains(selected, field) {
+			q.Select(field)
+		}
+	}
+	var works []*model.Work
+	for w, err := range q.All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		works = append(works, w)
+	}
+	return Compute(works, opts), nil
+}
+
+// HIndex returns the largest h such that h of the citation counts are at
+// least h.
+func HIndex(citations []int) int {
+	sorted := descending(citations)
+	h := 0
+	for i, c := range sorted {
+		if c < i+1 {
+			break
+		}
+		h = i + 1
+	}
+	return h
+}
+
+// GIndex returns the largest g, at most the number of counts, such that the g
+// largest citation counts add up to at least g².
+func GIndex(citations []int) int {
+	sorted := descending(citations)
+	g, total := 0, 0
+	for i, c := range sorted {
+		total += c
+		if total < (i+1)*(i+1) {
+			break
+		}
+		g = i + 1
+	}
+	return g
+}
+
+func descending(citations []int) []int {
+	sorted := slices.Clone(citations)
+	slices.SortFunc(sorted, func(a, b int) int { return cmp.Compare(b, a) })
+	return sorted
+}
+
+// citationsIn returns the citations w received in year.
+func citationsIn(w *model.Work, year int) int {
+	for _, c := range w.CountsByYear {
+		if c != nil && c.Year == year {
+			return c.CitedByCount
+		}
+	}
+	return 0
+}
+
+// distinct drops nil works and repeated works by ID, keeping the first.
+func distinct(works []*model.Work) []*model.Work {
+	seen := make(map[string]bool, len(works))
+	out := make([]*model.Work, 0, len(works))
+	for _, w := range works {
+		if w == nil {
+			continue
+		}
+		if w.ID != "" {
+			id := core.ShortID(w.ID)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		out = append(out, w)
+	}
+	return out
+}
@@ -0,0 +1,20 @@
+// Package storeutil computes bibliometric indicators over the works kept in a
+// local store. It is separate from package bibliometrics so that computing
+// indicators from queries does not depend on the store and its SQLite driver.
+package storeutil
+
+import (
+	"context"
+
+	"github.com/Sunhill666/goalex/pkg/bibliometrics"
+	"github.com/Sunhill666/goalex/pkg/store"
+)
+
+// FromStore computes the indicators of the works kept in s that match f.
+func FromStore(ctx context.Context, s *store.Store, f store.WorkFilter, opts bibliometrics.Options) (*bibliometrics.Indicators, error) {
+	works, err := s.Works(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	return bibliometrics.Compute(works, opts), nil
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Sunhill666/goalex/internal/model"
+)
+
+// CompletionGroup holds the global autocomplete results of one entity type.
+type CompletionGroup struct {
+	// EntityType is the entity type of the completions, such as author or source.
+	EntityType  string
+	Completions []*model.Completion
+}
+
+// AutoComplete suggests entities of every type whose names match the prefix
+// q, using the cross-entity /autocomplete endpoint. The results are grouped by
+// entity type; groups are ordered by their best-ranked completion and keep
+// the OpenAlex ranking within each group.
+func (c *Client) AutoComplete(ctx context.Context, q string) ([]*CompletionGroup, error) {
+	ctx, span := c.startSpan(ctx, "AutoComplete", Attribute{AttrEndpoint, EndPointAutoComplete})
+	resp, err := ListEntitiesWithContext[model.Completion](ctx, c, EndPointAutoComplete, &QueryParams{AutoComplete: q})
+	if err != nil {
+		endSpan(span, 0, nil, err)
+		return nil, err
+	}
+	endSpan(span, len(resp.Results), resp.Meta, nil)
+	return groupCompletions(resp.Results), nil
+}
+
+func groupCompletions(completions []*model.Completion) []*CompletionGroup {
+	var groups []*CompletionGroup
+	index := make(map[string]*CompletionGroup)
+	for _, comp := range completions {
+		g, ok := index[comp.EntityType]
+		if !ok {
+			g = &CompletionGroup{EntityType: comp.EntityType}
+			index[comp.EntityType] = g
+			groups = append(groups, g)
+		}
+		g.Completions = append(g.Completions, comp)
+	}
+	return groups
+}
+
+// completionFilterKeys are the works filters of entity types, for completions
+// that do not carry a filter key.
+var completionFilterKeys = map[string]string{
+	"work":        "ids.openalex",
+	"author":      "authorships.author.id",
+	"source":      "primary_location.source.id",
+	"institution": "authorships.institutions.lineage",
+	"publisher":   "primary_location.source.publisher_lineage",
+	"funder":      "grants.funder",
+	"topic":       "topics.id",
+	"keyword":     "keywords.id",
+	"concept":     "concepts.id",
+}
+
+// CompletionFilter returns the works filter that selects the works related to
+// comp, such as authorships.author.id and A5023888391 for an author. It uses
+// the filter key OpenAlex sent with the completion when there is one.
+func CompletionFilter(comp *model.Completion) (field, value string, err error) {
+	if comp == nil || comp.ID == "" {
+		return "", "", fmt.Errorf("completion has no ID")
+	}
+	field = comp.FilterKey
+	if field == "" {
+		field = completionFilterKeys[comp.EntityType]
+	}
+	if field == "" {
+		return "", "", fmt.Errorf("no works filter for completions of type %q", comp.EntityType)
+	}
+	return field, ShortID(comp.ID), nil
+}
+
+// WorksFor returns a works query filtered to the works related to comp, ready
+// for further filters, sorting or paging.
+func (c *Client) WorksFor(comp *model.Completion) (*QueryBuilder[model.Work], error) {
+	field, value, err := CompletionFilter(comp)
+	if err != nil {
+		return nil, err
+	}
+	return c.Works().Filter(field, value), nil
+}
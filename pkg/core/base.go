@@ -1,8 +1,11 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"path"
 
 	"github.com/Sunhill666/goalex/internal/model"
 )
@@ -12,6 +15,17 @@ func ListEntities[T any](
 	c *Client,
 	endpoint string,
 	params *QueryParams,
+) (*model.PaginatedResponse[T], error) {
+	return ListEntitiesWithContext[T](context.Background(), c, endpoint, params)
+}
+
+// ListEntitiesWithContext retrieves a paginated list of entities from the specified endpoint
+// with context support.
+func ListEntitiesWithContext[T any](
+	ctx context.Context,
+	c *Client,
+	endpoint string,
+	params *QueryParams,
 ) (*model.PaginatedResponse[T], error) {
 	q := url.Values{}
 	if params != nil {
@@ -21,9 +35,16 @@ func ListEntities[T any](
 	if encoded := q.Encode(); encoded != "" {
 		urlWithParams += "?" + encoded
 	}
+	if c.Logger != nil {
+		rendered, err := url.QueryUnescape(q.Encode())
+		if err != nil {
+			rendered = q.Encode()
+		}
+		c.Logger.DebugContext(ctx, "openalex query", "endpoint", endpoint, "query", rendered)
+	}
 
 	var resp model.PaginatedResponse[T]
-	err := c.Get(urlWithParams, &resp)
+	err := c.GetWithContext(ctx, urlWithParams, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -32,10 +53,73 @@ func ListEntities[T any](
 
 // GetEntity retrieves a single entity by ID from the specified endpoint.
 func GetEntity[T any](c *Client, endpoint, id string) (*T, error) {
+	return GetEntityWithContext[T](context.Background(), c, endpoint, id)
+}
+
+// GetEntityWithContext retrieves a single entity by ID from the specified endpoint
+// with context support. Merged entities are reported to the handler set with
+// WithMergeHandler.
+func GetEntityWithContext[T any](ctx context.Context, c *Client, endpoint, id string) (*T, error) {
+	if c.onMerge != nil {
+		entity, _, err := GetEntityWithResolution[T](ctx, c, endpoint, id)
+		return entity, err
+	}
 	var entity T
-	err := c.Get(fmt.Sprintf("%s/%s", endpoint, id), &entity)
+	err := c.GetWithContext(ctx, fmt.Sprintf("%s/%s", endpoint, id), &entity)
 	if err != nil {
 		return nil, err
 	}
 	return &entity, nil
 }
+
+// Resolution reports how OpenAlex answered a request for a single entity.
+type Resolution struct {
+	// RequestedID is the ID passed by the caller.
+	RequestedID string
+	// CanonicalID is the OpenAlex ID of the entity that was returned.
+	CanonicalID string
+	// Redirected reports whether the requested ID was merged into another
+	// entity, so that CanonicalID differs from RequestedID.
+	Redirected bool
+}
+
+// WithMergeHandler configures the client to call fn whenever a request for a
+// single entity, such as Get, is answered with the entity another ID was
+// merged into. Without a handler, Get follows merges silently; Resolve reports
+// them either way.
+func WithMergeHandler(fn func(Resolution)) Option {
+	return func(c *Client) {
+		c.onMerge = fn
+	}
+}
+
+// GetEntityWithResolution retrieves a single entity by ID like GetEntity, and
+// also reports whether OpenAlex redirected the request to a merged entity.
+func GetEntityWithResolution[T any](ctx context.Context, c *Client, endpoint, id string) (*T, *Resolution, error) {
+	var raw json.RawMessage
+	resp, err := c.get(ctx, fmt.Sprintf("%s/%s", endpoint, id), &raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	var entity T
+	if err := c.decodeBody(raw, &entity); err != nil {
+		return nil, nil, err
+	}
+	var ref struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(raw, &ref)
+
+	res := &Resolution{RequestedID: id, CanonicalID: ref.ID, Redirected: resp.Redirected}
+	if res.CanonicalID == "" {
+		res.CanonicalID = path.Base(resp.URL.Path)
+	}
+	// OpenAlex may also answer a merged ID directly with the surviving entity.
+	if IsOpenAlexID(id) && ShortID(id) != ShortID(res.CanonicalID) {
+		res.Redirected = true
+	}
+	if res.Redirected && c.onMerge != nil {
+		c.onMerge(*res)
+	}
+	return &entity, res, nil
+}
@@ -0,0 +1,67 @@
+package core
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Sunhill666/goalex/internal/ratelimit"
+)
+
+// Defaults of the limiter shared by the batch helpers of a client.
+const (
+	DefaultBatchRateLimit = 10
+	DefaultBatchBurst     = 4
+)
+
+// WithRateLimit configures the number of requests the client's batch helpers
+// start per second, and how many of them may start at once. The limit is
+// shared by every batch of the client, so concurrent batches stay within it
+// together. Defaults to DefaultBatchRateLimit and DefaultBatchBurst; a rate
+// that is not positive disables it.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(c *Client) {
+		c.limiter = ratelimit.New(perSecond, burst)
+	}
+}
+
+// BatchOptions configures helpers that issue many requests at once.
+type BatchOptions struct {
+	// Concurrency is the number of requests in flight. Defaults to 4.
+	Concurrency int
+}
+
+// batch calls fn for every index below n with bounded concurrency, at the
+// rate allowed by limiter. Calls not started before ctx is done get ctx.Err()
+// through skip.
+func batch(ctx context.Context, n int, limiter *ratelimit.Limiter, opts BatchOptions, fn func(ctx context.Context, i int), skip func(i int, err error)) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, opts.Concurrency)
+	for i := range n {
+		if err := ctx.Err(); err != nil {
+			skip(i, err)
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			skip(i, ctx.Err())
+			continue
+		}
+		if err := limiter.Wait(ctx); err != nil {
+			<-sem
+			skip(i, err)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(ctx, i)
+		}()
+	}
+	wg.Wait()
+}
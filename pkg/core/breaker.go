@@ -0,0 +1,237 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen matches the CircuitOpenError returned without contacting
+// OpenAlex while the circuit breaker is open:
+//
+//	if errors.Is(err, core.ErrCircuitOpen) { ... }
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned when the circuit breaker rejects an attempt.
+type CircuitOpenError struct {
+	// State is BreakerOpen, or BreakerHalfOpen when every trial slot is taken.
+	State BreakerState
+	// RetryAfter is when the breaker starts letting trial requests through.
+	// It is zero while half-open, when the outcome of the trials decides.
+	RetryAfter time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	if e.RetryAfter.IsZero() {
+		return fmt.Sprintf("circuit breaker is %s", e.State)
+	}
+	return fmt.Sprintf("circuit breaker is %s until %s", e.State, e.RetryAfter.Format(time.RFC3339))
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// BreakerState is the state of a circuit breaker.
+type BreakerState int
+
+// Circuit breaker states.
+const (
+	// BreakerClosed lets requests through and counts failures.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects requests with ErrCircuitOpen.
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of trial requests through.
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerOptions configures a Breaker.
+type BreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed attempts that opens
+	// the circuit. Defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before trial requests are
+	// let through. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of trial requests allowed while half-open,
+	// all of which must succeed to close the circuit. Defaults to 1.
+	HalfOpenRequests int
+	// IsFailure reports whether the error of an attempt counts as a failure.
+	// Defaults to 5xx responses, timeouts and other transport failures;
+	// 4xx responses and cancellations do not count.
+	IsFailure func(err error) bool
+	// OnStateChange is called after every state transition.
+	OnStateChange func(from, to BreakerState)
+}
+
+// Breaker is a circuit breaker that stops requests to OpenAlex during
+// outages. It may be shared by several clients.
+type Breaker struct {
+	opts BreakerOptions
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	trials    int
+	successes int
+	// round counts the half-open periods, so that trials admitted in an
+	// earlier one are not counted in the current one.
+	round int
+}
+
+// permit is an attempt let through by allow. Only trials, admitted while
+// half-open, hold one of the limited trial slots.
+type permit struct {
+	trial bool
+	round int
+}
+
+// NewBreaker returns a closed circuit breaker.
+func NewBreaker(opts BreakerOptions) *Breaker {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 30 * time.Second
+	}
+	if opts.HalfOpenRequests <= 0 {
+		opts.HalfOpenRequests = 1
+	}
+	if opts.IsFailure == nil {
+		opts.IsFailure = isOutage
+	}
+	return &Breaker{opts: opts}
+}
+
+// WithCircuitBreaker configures the client to check b before every attempt
+// and to report the outcome of each attempt to it.
+func WithCircuitBreaker(b *Breaker) Option {
+	return func(c *Client) {
+		c.Breaker = b
+	}
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.opts.OpenTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether an attempt may proceed, returning a
+// *CircuitOpenError if not. Every permitted attempt must be reported to record.
+func (b *Breaker) allow() (permit, error) {
+	if b == nil {
+		return permit{}, nil
+	}
+	b.mu.Lock()
+	from := b.state
+	if b.state == BreakerOpen {
+		if retryAfter := b.openedAt.Add(b.opts.OpenTimeout); time.Now().Before(retryAfter) {
+			b.mu.Unlock()
+			return permit{}, &CircuitOpenError{State: BreakerOpen, RetryAfter: retryAfter}
+		}
+		b.state, b.trials, b.successes = BreakerHalfOpen, 0, 0
+		b.round++
+	}
+	var p permit
+	var err error
+	if b.state == BreakerHalfOpen {
+		if b.trials >= b.opts.HalfOpenRequests {
+			err = &CircuitOpenError{State: BreakerHalfOpen}
+		} else {
+			b.trials++
+			p = permit{trial: true, round: b.round}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+	return p, err
+}
+
+// record reports the outcome of an attempt let through by allow. While
+// half-open, only the trials of the current half-open period count.
+func (b *Breaker) record(p permit, err error) {
+	if b == nil {
+		return
+	}
+	failure := err != nil && b.opts.IsFailure(err)
+	b.mu.Lock()
+	from := b.state
+	switch b.state {
+	case BreakerClosed:
+		if !failure {
+			b.failures = 0
+			break
+		}
+		b.failures++
+		if b.failures >= b.opts.FailureThreshold {
+			b.open()
+		}
+	case BreakerHalfOpen:
+		if !p.trial || p.round != b.round {
+			break
+		}
+		switch {
+		case failure:
+			b.open()
+		case errors.Is(err, context.Canceled):
+			// A cancelled trial says nothing about OpenAlex; free its slot.
+			b.trials--
+		default:
+			b.successes++
+			if b.successes >= b.opts.HalfOpenRequests {
+				b.state, b.failures = BreakerClosed, 0
+			}
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.notify(from, to)
+}
+
+func (b *Breaker) open() {
+	b.state, b.openedAt, b.failures = BreakerOpen, time.Now(), 0
+}
+
+func (b *Breaker) notify(from, to BreakerState) {
+	if from != to && b.opts.OnStateChange != nil {
+		b.opts.OnStateChange(from, to)
+	}
+}
+
+// isOutage reports whether err suggests that OpenAlex is unavailable.
+func isOutage(err error) bool {
+	var apiErr *APIError
+	var decErr *decodeError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.StatusCode >= 500
+	case errors.As(err, &decErr), errors.Is(err, context.Canceled):
+		return false
+	default:
+		// Timeouts, refused connections and other transport failures.
+		return true
+	}
+}
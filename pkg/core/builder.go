@@ -1,7 +1,11 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
+	"iter"
 	"maps"
+	"slices"
 
 	"github.com/Sunhill666/goalex/internal/model"
 )
@@ -11,6 +15,23 @@ type QueryBuilder[T any] struct {
 	client   *Client
 	endpoint string
 	params   *QueryParams
+	// cursorPage counts the pages fetched since the last cursor reset, for tracing.
+	cursorPage int
+}
+
+// Clone returns a copy of the query that can be changed without affecting q,
+// for helpers that add their own parameters to a caller's query.
+func (q *QueryBuilder[T]) Clone() *QueryBuilder[T] {
+	params := *q.params
+	if q.params.Pagination != nil {
+		pagination := *q.params.Pagination
+		params.Pagination = &pagination
+	}
+	params.Filter = maps.Clone(q.params.Filter)
+	params.Sort = maps.Clone(q.params.Sort)
+	params.Select = slices.Clone(q.params.Select)
+	params.GroupBys = slices.Clone(q.params.GroupBys)
+	return &QueryBuilder[T]{client: q.client, endpoint: q.endpoint, params: &params}
 }
 
 // Page sets the page number for pagination.
@@ -100,6 +121,12 @@ func (q *QueryBuilder[T]) Select(fields ...string) *QueryBuilder[T] {
 	return q
 }
 
+// Selected returns the fields selected so far, for helpers that add their own
+// fields to a caller's query without repeating them.
+func (q *QueryBuilder[T]) Selected() []string {
+	return slices.Clone(q.params.Select)
+}
+
 // Sample sets the sample size for random sampling.
 func (q *QueryBuilder[T]) Sample(sample int) *QueryBuilder[T] {
 	if sample <= 0 {
@@ -118,12 +145,49 @@ func (q *QueryBuilder[T]) Seed(seed int) *QueryBuilder[T] {
 
 // Get retrieves a single entity by its ID.
 func (q *QueryBuilder[T]) Get(id string) (*T, error) {
-	return GetEntity[T](q.client, q.endpoint, id)
+	return q.get(context.Background(), "Get", id)
+}
+
+// GetWithContext retrieves a single entity by its ID with context support.
+func (q *QueryBuilder[T]) GetWithContext(ctx context.Context, id string) (*T, error) {
+	return q.get(ctx, "Get", id)
+}
+
+// Resolve retrieves a single entity by its ID and reports its canonical ID,
+// which differs from id when OpenAlex has merged the requested entity into another.
+func (q *QueryBuilder[T]) Resolve(ctx context.Context, id string) (*T, *Resolution, error) {
+	ctx, span := q.client.startSpan(ctx, operation(q.endpoint, "Resolve"), Attribute{AttrEndpoint, q.endpoint})
+	entity, res, err := GetEntityWithResolution[T](ctx, q.client, q.endpoint, id)
+	endSpan(span, 1, nil, err)
+	return entity, res, err
 }
 
 // GetRandom retrieves a random entity.
 func (q *QueryBuilder[T]) GetRandom() (*T, error) {
-	return GetEntity[T](q.client, q.endpoint, "random")
+	return q.get(context.Background(), "GetRandom", "random")
+}
+
+// GetRaw retrieves a single entity by its ID as undecoded JSON, which gives
+// access to fields the models do not capture.
+func (q *QueryBuilder[T]) GetRaw(ctx context.Context, id string) (json.RawMessage, error) {
+	raw, err := getAs[json.RawMessage](ctx, q, "GetRaw", id)
+	if err != nil {
+		return nil, err
+	}
+	return *raw, nil
+}
+
+func (q *QueryBuilder[T]) get(ctx context.Context, op, id string) (*T, error) {
+	return getAs[T](ctx, q, op, id)
+}
+
+// getAs retrieves a single entity of the query's endpoint, decoded into U,
+// within a span named after the operation.
+func getAs[U, T any](ctx context.Context, q *QueryBuilder[T], op, id string) (*U, error) {
+	ctx, span := q.client.startSpan(ctx, operation(q.endpoint, op), Attribute{AttrEndpoint, q.endpoint})
+	entity, err := GetEntityWithContext[U](ctx, q.client, q.endpoint, id)
+	endSpan(span, 1, nil, err)
+	return entity, err
 }
 
 // GroupBy adds a group by parameter to the query with optional inclusion of unknown values.
@@ -135,6 +199,13 @@ func (q *QueryBuilder[T]) GroupBy(field string, includeUnknown bool) *QueryBuild
 	return q
 }
 
+// GroupBys groups by several fields in one request. Each field may carry the
+// :include_unknown suffix. Use ListGroupBys to read the groups of each field.
+func (q *QueryBuilder[T]) GroupBys(fields ...string) *QueryBuilder[T] {
+	q.params.GroupBys = append(q.params.GroupBys, fields...)
+	return q
+}
+
 // AutoComplete creates a new query builder for autocomplete suggestions.
 func (q *QueryBuilder[T]) AutoComplete(query string) *QueryBuilder[model.Completion] {
 	autoCompleteBuilder := &QueryBuilder[model.Completion]{
@@ -148,7 +219,7 @@ func (q *QueryBuilder[T]) AutoComplete(query string) *QueryBuilder[model.Complet
 
 // List executes the query and returns a list of entities.
 func (q *QueryBuilder[T]) List() ([]*T, error) {
-	resp, err := ListEntities[T](q.client, q.endpoint, q.params)
+	resp, err := q.list(context.Background(), "List")
 	if err != nil {
 		return nil, err
 	}
@@ -157,28 +228,200 @@ func (q *QueryBuilder[T]) List() ([]*T, error) {
 
 // ListGroupBy executes the query and returns grouped results.
 func (q *QueryBuilder[T]) ListGroupBy() ([]*model.GroupBy, error) {
-	resp, err := ListEntities[T](q.client, q.endpoint, q.params)
+	return q.ListGroupByWithContext(context.Background())
+}
+
+// ListGroupByWithContext executes the query with context support and returns
+// grouped results.
+func (q *QueryBuilder[T]) ListGroupByWithContext(ctx context.Context) ([]*model.GroupBy, error) {
+	resp, err := q.list(ctx, "GroupBy")
 	if err != nil {
 		return nil, err
 	}
 	return resp.GroupBy, nil
 }
 
+// ListGroupBys executes a query grouped by several fields and returns the
+// groups keyed by field.
+func (q *QueryBuilder[T]) ListGroupBys() (map[string][]*model.GroupBy, *model.PaginatedResponseMeta, error) {
+	return q.ListGroupBysWithContext(context.Background())
+}
+
+// ListGroupBysWithContext executes a query grouped by several fields with
+// context support and returns the groups keyed by field.
+func (q *QueryBuilder[T]) ListGroupBysWithContext(ctx context.Context) (map[string][]*model.GroupBy, *model.PaginatedResponseMeta, error) {
+	resp, err := q.list(ctx, "GroupBy")
+	if err != nil {
+		return nil, nil, err
+	}
+	groups := make(map[string][]*model.GroupBy, len(resp.GroupBys))
+	for _, g := range resp.GroupBys {
+		groups[g.Field] = g.Groups
+	}
+	return groups, resp.Meta, nil
+}
+
+// AllGroups iterates over every group of a query grouped by a single field,
+// using cursor pagination so that high-cardinality fields such as
+// authorships.author.id are not cut off after the first page. Pages hold 200
+// groups unless PerPage says otherwise. Iteration stops at the first error,
+// which is also yielded when the query has no group-by field. The pages are
+// requested with a clone of q, so q itself is not changed.
+func (q *QueryBuilder[T]) AllGroups(ctx context.Context) iter.Seq2[*model.GroupBy, error] {
+	return func(yield func(*model.GroupBy, error) bool) {
+		if q.params.GroupBy == "" {
+			yield(nil, errNoGroupBy)
+			return
+		}
+		q := q.Clone()
+		if q.params.Pagination == nil || q.params.Pagination.PerPage == 0 {
+			q.PerPage(200)
+		}
+		cursor := "*"
+		for cursor != "" {
+			q.params.Cursor = cursor
+			resp, err := q.list(ctx, "GroupBy")
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, g := range resp.GroupBy {
+				if !yield(g, nil) {
+					return
+				}
+			}
+			if len(resp.GroupBy) == 0 || resp.Meta == nil {
+				return
+			}
+			cursor = resp.Meta.NextCursor
+		}
+	}
+}
+
 // Cursor executes the query using cursor-based pagination and returns results with next cursor.
 func (q *QueryBuilder[T]) Cursor(cursor ...string) ([]*T, string, error) {
+	return q.CursorWithContext(context.Background(), cursor...)
+}
+
+// CursorWithContext executes the query using cursor-based pagination with context support
+// and returns results with next cursor.
+func (q *QueryBuilder[T]) CursorWithContext(ctx context.Context, cursor ...string) ([]*T, string, error) {
 	if len(cursor) > 0 {
 		q.params.Cursor = cursor[0]
 	} else {
 		q.params.Cursor = "*"
 	}
-	resp, err := ListEntities[T](q.client, q.endpoint, q.params)
+	if q.params.Cursor == "*" {
+		q.cursorPage = 1
+	} else {
+		q.cursorPage++
+	}
+	ctx, span := q.client.startSpan(ctx, operation(q.endpoint, "Cursor"),
+		append(spanAttributes(q.endpoint, q.params), Attribute{AttrCursorPage, q.cursorPage})...)
+	resp, err := ListEntitiesWithContext[T](ctx, q.client, q.endpoint, q.params)
 	if err != nil {
+		endSpan(span, 0, nil, err)
 		return nil, "", err
 	}
+	endSpan(span, len(resp.Results), resp.Meta, nil)
+	if resp.Meta == nil {
+		return resp.Results, "", nil
+	}
 	return resp.Results, resp.Meta.NextCursor, nil
 }
 
 // ListWithMeta executes the query and returns results with metadata.
 func (q *QueryBuilder[T]) ListWithMeta() (*model.PaginatedResponse[T], error) {
-	return ListEntities[T](q.client, q.endpoint, q.params)
+	return q.list(context.Background(), "List")
+}
+
+// ListWithMetaContext executes the query with context support and returns results with metadata.
+func (q *QueryBuilder[T]) ListWithMetaContext(ctx context.Context) (*model.PaginatedResponse[T], error) {
+	return q.list(ctx, "List")
+}
+
+// ListRaw executes the query and returns every result as undecoded JSON
+// together with the metadata, which gives access to fields the models do not
+// capture.
+func (q *QueryBuilder[T]) ListRaw(ctx context.Context) ([]json.RawMessage, *model.PaginatedResponseMeta, error) {
+	resp, err := listAs[json.RawMessage](ctx, q, "ListRaw")
+	if err != nil {
+		return nil, nil, err
+	}
+	results := make([]json.RawMessage, len(resp.Results))
+	for i, r := range resp.Results {
+		if r != nil {
+			results[i] = *r
+		}
+	}
+	return results, resp.Meta, nil
+}
+
+// ListAs executes the query and decodes every result into U instead of the
+// entity model, which suits partial responses requested with Select:
+//
+//	type ref struct {
+//		ID  string `json:"id"`
+//		DOI string `json:"doi"`
+//	}
+//	refs, meta, err := core.ListAs[ref](ctx, client.Works().Select("id", "doi"))
+func ListAs[U, T any](ctx context.Context, q *QueryBuilder[T]) ([]*U, *model.PaginatedResponseMeta, error) {
+	resp, err := listAs[U](ctx, q, "ListAs")
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Results, resp.Meta, nil
+}
+
+// list executes the query within a span named after the operation.
+func (q *QueryBuilder[T]) list(ctx context.Context, op string) (*model.PaginatedResponse[T], error) {
+	return listAs[T](ctx, q, op)
+}
+
+// listAs executes the query, decoding results into U, within a span named
+// after the operation.
+func listAs[U, T any](ctx context.Context, q *QueryBuilder[T], op string) (*model.PaginatedResponse[U], error) {
+	grouped := q.params.GroupBy != "" || len(q.params.GroupBys) > 0
+	if grouped {
+		op = "GroupBy"
+	}
+	ctx, span := q.client.startSpan(ctx, operation(q.endpoint, op), spanAttributes(q.endpoint, q.params)...)
+	resp, err := ListEntitiesWithContext[U](ctx, q.client, q.endpoint, q.params)
+	if err != nil {
+		endSpan(span, 0, nil, err)
+		return nil, err
+	}
+	results := len(resp.Results)
+	if grouped {
+		results = len(resp.GroupBy)
+		for _, g := range resp.GroupBys {
+			results += len(g.Groups)
+		}
+	}
+	endSpan(span, results, resp.Meta, nil)
+	return resp, nil
+}
+
+// All iterates over every result of the query using cursor-based pagination,
+// fetching pages lazily as the caller advances. Iteration stops at the first error.
+func (q *QueryBuilder[T]) All(ctx context.Context) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		cursor := "*"
+		for cursor != "" {
+			results, next, err := q.CursorWithContext(ctx, cursor)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, r := range results {
+				if !yield(r, nil) {
+					return
+				}
+			}
+			if len(results) == 0 {
+				return
+			}
+			cursor = next
+		}
+	}
 }
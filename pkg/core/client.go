@@ -1,23 +1,47 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
+
+	"github.com/Sunhill666/goalex/internal/ratelimit"
 )
 
 // Client represents an HTTP client for interacting with the OpenAlex API.
 type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
+	Middleware []Middleware
+	Logger     *slog.Logger
+	Tracer     Tracer
+	Meter      Meter
+	Breaker    *Breaker
 	MailTo     string
 	Token      string
 	Timeout    time.Duration
 	MaxRetries int
 	RetryDelay time.Duration
+
+	// flights deduplicates concurrent identical requests when coalescing is enabled.
+	flights *flightGroup
+	// extra, strict and onUnknown configure the handling of fields the models
+	// do not capture.
+	extra     bool
+	strict    bool
+	onUnknown func(UnknownFields)
+	// onMerge is told about single-entity requests answered with a merged entity.
+	onMerge func(Resolution)
+	// limiter paces the requests of the batch helpers.
+	limiter *ratelimit.Limiter
 }
 
 // Option is a function type for configuring the Client.
@@ -37,6 +61,15 @@ func Auth(token string) Option {
 	}
 }
 
+// WithBaseURL configures the client to send requests to baseURL instead of
+// https://api.openalex.org, such as a mirror or a goalex-proxy. Requests go
+// below the path of baseURL, as in https://example.org/openalex/works.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.BaseURL = baseURL
+	}
+}
+
 // WithTimeout configures the client's timeout duration.
 func WithTimeout(timeout time.Duration) Option {
 	return func(c *Client) {
@@ -53,6 +86,15 @@ func WithRetry(maxRetries int, retryDelay time.Duration) Option {
 	}
 }
 
+// WithLogger configures the client to log every request attempt to logger.
+// Successful attempts and rendered queries are logged at debug level, retried
+// failures at warn level and final failures at error level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.Logger = logger
+	}
+}
+
 // WithHTTPClient configures the client to use a custom HTTP client.
 func WithHTTPClient(client *http.Client) Option {
 	return func(c *Client) {
@@ -71,6 +113,7 @@ func New(opts ...Option) *Client {
 		Timeout:    10 * time.Second,
 		MaxRetries: 3,
 		RetryDelay: time.Second,
+		limiter:    ratelimit.New(DefaultBatchRateLimit, DefaultBatchBurst),
 	}
 	for _, opt := range opts {
 		opt(c)
@@ -78,6 +121,30 @@ func New(opts ...Option) *Client {
 	return c
 }
 
+// APIError is returned when OpenAlex answers with an error status.
+type APIError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Status)
+}
+
+// isRetryable reports whether the error of an attempt is worth retrying.
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	var decErr *decodeError
+	switch {
+	case errors.As(err, &apiErr):
+		return isRetryableStatusCode(apiErr.StatusCode)
+	case errors.As(err, &decErr):
+		return false
+	default:
+		return isRetryableError(err)
+	}
+}
+
 func isRetryableError(err error) bool {
 	if err == nil {
 		return false
@@ -109,14 +176,66 @@ func (c *Client) Get(path string, out any) error {
 // GetWithContext performs a GET request to the specified path with context support
 // and decodes the response into out.
 func (c *Client) GetWithContext(ctx context.Context, path string, out any) error {
+	_, err := c.get(ctx, path, out)
+	return err
+}
+
+// response describes how a successful request was answered.
+type response struct {
+	// URL is the URL of the final request after following redirects.
+	URL *url.URL
+	// Redirected reports whether the server redirected the request.
+	Redirected bool
+}
+
+func (c *Client) get(ctx context.Context, path string, out any) (*response, error) {
+	u, err := c.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	if c.flights != nil {
+		body, resp, err := c.flights.do(ctx, u.String(), func(ctx context.Context, body *json.RawMessage) (*response, error) {
+			return c.fetch(ctx, http.MethodGet, u, nil, body)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := c.decodeBody(body, out); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+	return c.fetch(ctx, http.MethodGet, u, nil, out)
+}
+
+// post sends in as a JSON body to the specified path and decodes the response
+// into out. Unlike GET requests, POST requests are never coalesced.
+func (c *Client) post(ctx context.Context, path string, in, out any) (*response, error) {
+	u, err := c.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+	return c.fetch(ctx, http.MethodPost, u, body, out)
+}
+
+// resolve turns path into an absolute URL with the credential parameters set.
+func (c *Client) resolve(path string) (*url.URL, error) {
 	base, err := url.Parse(c.BaseURL)
 	if err != nil {
-		return fmt.Errorf("invalid base URL: %w", err)
+		return nil, fmt.Errorf("invalid base URL: %w", err)
 	}
 
-	rel, err := url.Parse(path)
+	// Paths such as /works are resolved below the base URL's own path, so
+	// that a base URL such as https://example.org/openalex keeps its prefix.
+	base.Path = strings.TrimSuffix(base.Path, "/") + "/"
+	base.RawPath = ""
+	rel, err := url.Parse("./" + strings.TrimPrefix(path, "/"))
 	if err != nil {
-		return fmt.Errorf("invalid path: %w", err)
+		return nil, fmt.Errorf("invalid path: %w", err)
 	}
 
 	u := base.ResolveReference(rel)
@@ -130,49 +249,72 @@ func (c *Client) GetWithContext(ctx context.Context, path string, out any) error
 	}
 
 	u.RawQuery = q.Encode()
+	return u, nil
+}
 
+// fetch requests u, retrying transient failures, and decodes the response into
+// out. A non-nil body is sent as JSON with every attempt.
+func (c *Client) fetch(ctx context.Context, method string, u *url.URL, body []byte, out any) (*response, error) {
 	var lastErr error
 	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
 		if attempt > 0 {
 			// If not the first attempt, wait for a while before retrying
 			select {
 			case <-ctx.Done():
-				return ctx.Err()
+				return nil, ctx.Err()
 			case <-time.After(c.RetryDelay * time.Duration(attempt)):
 			}
 		}
 
-		req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
 		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
 		}
 
-		resp, err := c.HTTPClient.Do(req)
+		// The breaker is asked once the request is built, so every attempt it
+		// lets through is reported back to it.
+		permit, err := c.Breaker.allow()
 		if err != nil {
-			lastErr = err
-			if isRetryableError(err) && attempt < c.MaxRetries {
-				continue
-			}
-			return fmt.Errorf("request failed after %d attempts: %w", attempt+1, err)
+			return nil, err
 		}
-
-		if resp.StatusCode >= 400 {
-			_ = resp.Body.Close()
-			if isRetryableStatusCode(resp.StatusCode) && attempt < c.MaxRetries {
-				lastErr = fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-				continue
+		start := time.Now()
+		resp, size, err := c.do(req, attempt+1, out)
+		c.Breaker.record(permit, err)
+		retry := err != nil && isRetryable(err) && attempt < c.MaxRetries
+		duration := time.Since(start)
+		c.logAttempt(ctx, req, attempt+1, resp, size, duration, err, retry)
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		c.recordAttempt(ctx, req.URL, status, duration, err, retry)
+		if err == nil {
+			// After following redirects, resp.Request is the last request made.
+			final := req.URL
+			if resp.Request != nil {
+				final = resp.Request.URL
 			}
-			return fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+			return &response{URL: final, Redirected: final.String() != req.URL.String()}, nil
 		}
-
-		defer func() { _ = resp.Body.Close() }()
-		err = json.NewDecoder(resp.Body).Decode(out)
-		if err != nil {
-			return fmt.Errorf("failed to decode response: %w", err)
+		if retry {
+			lastErr = err
+			continue
 		}
 
-		return nil
+		var apiErr *APIError
+		var decErr *decodeError
+		if errors.As(err, &apiErr) || errors.As(err, &decErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("request failed after %d attempts: %w", attempt+1, err)
 	}
 
-	return fmt.Errorf("request failed after %d attempts, last error: %w", c.MaxRetries+1, lastErr)
+	return nil, fmt.Errorf("request failed after %d attempts, last error: %w", c.MaxRetries+1, lastErr)
 }
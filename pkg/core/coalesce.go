@@ -0,0 +1,165 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// WithRequestCoalescing configures the client to share one HTTP round-trip
+// between concurrent requests for the same URL. Each caller decodes its own
+// copy of the shared response, so results are never aliased. A caller whose
+// context is done returns immediately; the shared request is cancelled only
+// once every caller waiting on it has given up. Its deadline is the latest of
+// the callers' deadlines, and it has none while a caller without a deadline
+// waits on it; each attempt is still bounded by the HTTP client's timeout.
+func WithRequestCoalescing() Option {
+	return func(c *Client) {
+		c.flights = &flightGroup{calls: make(map[string]*flight)}
+	}
+}
+
+// flightGroup deduplicates concurrent requests by URL.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// flight is a request shared by the callers waiting on it.
+type flight struct {
+	done    chan struct{}
+	body    json.RawMessage
+	resp    *response
+	err     error
+	waiters int
+	ctx     *flightContext
+}
+
+// do returns the body fetched for key, joining a request already in flight
+// or starting one. The request runs detached from the caller that starts it,
+// until the latest deadline of its callers. A caller without a deadline lifts
+// the deadline, so that retries and their delays run as they would without
+// coalescing.
+func (g *flightGroup) do(ctx context.Context, key string, fetch func(context.Context, *json.RawMessage) (*response, error)) (json.RawMessage, *response, error) {
+	// A zero deadline lifts the flight's deadline.
+	deadline, _ := ctx.Deadline()
+
+	g.mu.Lock()
+	f, ok := g.calls[key]
+	if !ok || f.ctx.Err() != nil {
+		// A flight past its deadline is left to fail; later callers start afresh.
+		f = &flight{done: make(chan struct{}), ctx: newFlightContext(ctx)}
+		g.calls[key] = f
+		go func() {
+			f.resp, f.err = fetch(f.ctx, &f.body)
+			g.mu.Lock()
+			g.forget(key, f)
+			g.mu.Unlock()
+			f.ctx.stop()
+			close(f.done)
+		}()
+	}
+	f.ctx.extend(deadline)
+	f.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-f.done:
+		return f.body, f.resp, f.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		f.waiters--
+		if f.waiters == 0 {
+			// Nobody is left to use the response; later callers start afresh.
+			f.ctx.stop()
+			g.forget(key, f)
+		}
+		g.mu.Unlock()
+		return nil, nil, ctx.Err()
+	}
+}
+
+// forget removes f from the group unless a newer flight has replaced it.
+// g.mu must be held.
+func (g *flightGroup) forget(key string, f *flight) {
+	if g.calls[key] == f {
+		delete(g.calls, key)
+	}
+}
+
+// flightContext is the context of a shared request. It keeps the values of
+// the context it was started with but none of its cancellation, and its
+// deadline moves to the latest deadline of the callers waiting on it.
+type flightContext struct {
+	context.Context
+	cancel context.CancelCauseFunc
+
+	mu        sync.Mutex
+	deadline  time.Time
+	unbounded bool
+	timer     *time.Timer
+}
+
+func newFlightContext(ctx context.Context) *flightContext {
+	base, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	return &flightContext{Context: base, cancel: cancel}
+}
+
+func (c *flightContext) Deadline() (time.Time, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unbounded || c.deadline.IsZero() {
+		return time.Time{}, false
+	}
+	return c.deadline, true
+}
+
+// Err returns context.DeadlineExceeded once the deadline has passed.
+func (c *flightContext) Err() error {
+	if c.Context.Err() == nil {
+		return nil
+	}
+	return context.Cause(c.Context)
+}
+
+// extend moves the deadline to d if that is later. A zero d lifts the deadline.
+func (c *flightContext) extend(d time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case c.unbounded:
+	case d.IsZero():
+		c.unbounded = true
+		if c.timer != nil {
+			c.timer.Stop()
+		}
+	case d.After(c.deadline):
+		c.deadline = d
+		if c.timer == nil {
+			c.timer = time.AfterFunc(time.Until(d), c.expire)
+		} else {
+			c.timer.Reset(time.Until(d))
+		}
+	}
+}
+
+// expire cancels the context unless the deadline has moved since the timer
+// was set.
+func (c *flightContext) expire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.unbounded && !time.Now().Before(c.deadline) {
+		c.cancel(context.DeadlineExceeded)
+	}
+}
+
+// stop cancels the context and releases its timer.
+func (c *flightContext) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.cancel(context.Canceled)
+}
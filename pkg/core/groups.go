@@ -0,0 +1,376 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Sunhill666/goalex/internal/model"
+)
+
+// GroupKey constrains the Go types that group-by keys are parsed into: int for
+// numeric fields such as publication_year, bool for flags such as
+// open_access.is_oa, and string for entity IDs and other values.
+type GroupKey interface {
+	int | bool | string
+}
+
+// Group is a group-by group with its key parsed.
+type Group[K GroupKey] struct {
+	// Key is the parsed key. Entity IDs are shortened, so
+	// https://openalex.org/A5023888391 becomes A5023888391 and
+	// https://openalex.org/work-types/article becomes article. It is the zero
+	// value for the unknown group.
+	Key K
+	// RawKey is the key as OpenAlex sent it.
+	RawKey      string
+	DisplayName string
+	Count       int
+	// Unknown reports whether this is the group of results without a value,
+	// requested with the include_unknown option.
+	Unknown bool
+	// Share is Count relative to the number of results the groups were drawn
+	// from, or 0 when that is not known. Shares of multi-valued fields such as
+	// authorships.countries can add up to more than 1.
+	Share float64
+}
+
+// Percent returns the share of the group as a percentage.
+func (g *Group[K]) Percent() float64 {
+	return g.Share * 100
+}
+
+// Groups are the parsed groups of one group-by field.
+type Groups[K GroupKey] struct {
+	Field string
+	// Total is the number of results the groups were drawn from, meta.count.
+	Total  int
+	Groups []*Group[K]
+}
+
+// Get returns the group with the given key, other than the unknown group.
+func (g *Groups[K]) Get(key K) (*Group[K], bool) {
+	for _, group := range g.Groups {
+		if !group.Unknown && group.Key == key {
+			return group, true
+		}
+	}
+	return nil, false
+}
+
+// Unknown returns the group of results without a value, if it was requested.
+func (g *Groups[K]) Unknown() (*Group[K], bool) {
+	for _, group := range g.Groups {
+		if group.Unknown {
+			return group, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the keys of the groups in order, leaving out the unknown group.
+func (g *Groups[K]) Keys() []K {
+	keys := make([]K, 0, len(g.Groups))
+	for _, group := range g.Groups {
+		if !group.Unknown {
+			keys = append(keys, group.Key)
+		}
+	}
+	return keys
+}
+
+// errNoGroupBy is returned by the helpers for a single group-by field when the
+// query has none.
+var errNoGroupBy = errors.New("query has no group-by field")
+
+// ListGroups executes a query grouped by a single field and parses its groups:
+//
+//	years, err := core.ListGroups[int](ctx, client.Works().GroupBy("publication_year", false))
+func ListGroups[K GroupKey, T any](ctx context.Context, q *QueryBuilder[T]) (*Groups[K], error) {
+	if q.params.GroupBy == "" {
+		return nil, errNoGroupBy
+	}
+	resp, err := q.list(ctx, "GroupBy")
+	if err != nil {
+		return nil, err
+	}
+	field, _, _ := strings.Cut(q.params.GroupBy, ":")
+	return ParseGroups[K](field, resp.GroupBy, resp.Meta)
+}
+
+// ParseGroups parses the groups of field, such as those returned by
+// ListGroupBy or ListGroupBysWithContext, computing shares relative to meta.count.
+func ParseGroups[K GroupKey](field string, groups []*model.GroupBy, meta *model.PaginatedResponseMeta) (*Groups[K], error) {
+	parsed := &Groups[K]{Field: field, Groups: make([]*Group[K], 0, len(groups))}
+	if meta != nil {
+		parsed.Total = meta.Count
+	}
+	for _, g := range groups {
+		group := &Group[K]{RawKey: g.Key, DisplayName: g.KeyDisplayName, Count: g.Count}
+		if g.Key == "" || g.Key == "unknown" {
+			group.Unknown = true
+		} else if err := parseGroupKey(g.Key, &group.Key); err != nil {
+			return nil, fmt.Errorf("group %q of %s: %w", g.Key, field, err)
+		}
+		if parsed.Total > 0 {
+			group.Share = float64(g.Count) / float64(parsed.Total)
+		}
+		parsed.Groups = append(parsed.Groups, group)
+	}
+	return parsed, nil
+}
+
+func parseGroupKey[K GroupKey](raw string, key *K) error {
+	switch k := any(key).(type) {
+	case *int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("not an integer key")
+		}
+		*k = n
+	case *bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("not a boolean key")
+		}
+		*k = b
+	case *string:
+		if strings.Contains(raw, "openalex.org/") {
+			raw = treeKey(raw)
+		}
+		*k = raw
+	}
+	return nil
+}
+
+// EntityGroup is a group keyed by an entity, together with the entity.
+type EntityGroup[E any] struct {
+	*Group[string]
+	// Entity is nil for the unknown group, for keys that are not entity IDs
+	// and for entities that could not be fetched.
+	Entity *E
+}
+
+// maxHydrateIDs is the number of IDs OpenAlex accepts in one OR filter.
+const maxHydrateIDs = 100
+
+// hydrateRequest fetches the entities of one endpoint: up to maxHydrateIDs
+// native IDs with an openalex filter, or a single entity whose ID carries its
+// type as a path segment.
+type hydrateRequest struct {
+	endpoint string
+	ids      []string
+	filter   bool
+}
+
+// HydrateGroups fetches the entities that key groups, such as the authors of
+// a group-by on authorships.author.id or the subfields of one on
+// primary_topic.subfield.id, decoding them into E. Entities with native IDs
+// are fetched 100 at a time, the others, such as keywords and subfields, one
+// at a time. Only the top-level fields of E and id are selected, so small
+// types keep requests small, while a full model such as model.Author selects
+// every field:
+//
+//	type author struct {
+//		DisplayName string `json:"display_name"`
+//	}
+//	authors, err := core.HydrateGroups[author](ctx, client, groups, core.BatchOptions{})
+//
+// Requests share the rate limit of the client's batch helpers. Groups are
+// returned in order. When a request fails, the entities it would have fetched
+// are left nil and the first error is returned; groups whose keys are not
+// entity IDs are left nil and reported with an error too.
+func HydrateGroups[E any](ctx context.Context, c *Client, groups *Groups[string], opts BatchOptions) ([]*EntityGroup[E], error) {
+	hydrated := make([]*EntityGroup[E], len(groups.Groups))
+	var requests []hydrateRequest
+	open := make(map[string]int)
+	seen := make(map[string]bool)
+	var unkeyed []string
+	for i, g := range groups.Groups {
+		hydrated[i] = &EntityGroup[E]{Group: g}
+		if g.Unknown {
+			continue
+		}
+		endpoint, filter, ok := entityEndpoint(g.RawKey)
+		if !ok {
+			unkeyed = append(unkeyed, g.RawKey)
+			continue
+		}
+		if seen[endpoint+"/"+g.Key] {
+			continue
+		}
+		seen[endpoint+"/"+g.Key] = true
+		if j, ok := open[endpoint]; filter && ok && len(requests[j].ids) < maxHydrateIDs {
+			requests[j].ids = append(requests[j].ids, g.Key)
+			continue
+		}
+		if filter {
+			open[endpoint] = len(requests)
+		}
+		requests = append(requests, hydrateRequest{endpoint: endpoint, ids: []string{g.Key}, filter: filter})
+	}
+
+	// The id member keys the entities, whether or not E has it.
+	fields := selectFields(reflect.TypeFor[E]())
+	if fields != nil && !slices.Contains(fields, "id") {
+		fields = append(fields, "id")
+		slices.Sort(fields)
+	}
+	bodies := make([][]json.RawMessage, len(requests))
+	errs := make([]error, len(requests))
+	batch(ctx, len(requests), c.limiter, opts, func(ctx context.Context, i int) {
+		r := requests[i]
+		if !r.filter {
+			path := r.endpoint + "/" + url.PathEscape(r.ids[0])
+			if fields != nil {
+				path += "?select=" + strings.Join(fields, ",")
+			}
+			var raw json.RawMessage
+			if errs[i] = c.GetWithContext(ctx, path, &raw); errs[i] == nil {
+				bodies[i] = []json.RawMessage{raw}
+			}
+			return
+		}
+		q := Query[json.RawMessage](c, r.endpoint).
+			Filter(FilterOpenAlexID, strings.Join(r.ids, "|")).
+			PerPage(len(r.ids)).
+			Select(fields...)
+		resp, err := q.list(ctx, "Hydrate")
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		for _, raw := range resp.Results {
+			bodies[i] = append(bodies[i], *raw)
+		}
+	}, func(i int, err error) {
+		errs[i] = err
+	})
+
+	byKey := make(map[string]*E)
+	for i, raws := range bodies {
+		for _, raw := range raws {
+			var ref struct {
+				ID string `json:"id"`
+			}
+			if json.Unmarshal(raw, &ref) != nil || ref.ID == "" {
+				continue
+			}
+			entity := new(E)
+			if err := c.decodeBody(raw, entity); err != nil {
+				errs[i] = cmp.Or(errs[i], err)
+				continue
+			}
+			byKey[requests[i].endpoint+"/"+treeKey(ref.ID)] = entity
+		}
+	}
+	for _, g := range hydrated {
+		if endpoint, _, ok := entityEndpoint(g.RawKey); ok && !g.Unknown {
+			g.Entity = byKey[endpoint+"/"+g.Key]
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return hydrated, err
+		}
+	}
+	if len(unkeyed) > 0 {
+		return hydrated, fmt.Errorf("%d groups are not keyed by entity IDs, such as %q", len(unkeyed), unkeyed[0])
+	}
+	return hydrated, nil
+}
+
+// entityEndpoints maps the letters of native OpenAlex IDs to their endpoints.
+var entityEndpoints = map[byte]string{
+	'W': EndpointWorks,
+	'A': EndpointAuthors,
+	'S': EndpointSources,
+	'I': EndpointInstitutions,
+	'T': EndpointTopics,
+	'P': EndpointPublishers,
+	'F': EndpointFunders,
+	'C': EndpointConcepts,
+}
+
+// typedEndpoints serve the entities whose IDs carry their type as a path
+// segment, such as https://openalex.org/subfields/1702.
+var typedEndpoints = []string{
+	EndpointKeywords,
+	EndpointDomains,
+	EndpointFields,
+	EndpointSubfields,
+	EndpointCountries,
+	EndpointContinents,
+	EndpointSDGs,
+	EndpointLanguages,
+	EndpointLicenses,
+	EndpointWorkTypes,
+	EndpointSourceTypes,
+	EndpointInstitutionTypes,
+}
+
+// entityEndpoint returns the endpoint serving the entity with the given ID,
+// and whether the entity can be fetched with an openalex filter, which only
+// accepts native IDs.
+func entityEndpoint(id string) (endpoint string, filter bool, ok bool) {
+	for _, endpoint := range typedEndpoints {
+		if strings.Contains(id, "openalex.org"+endpoint+"/") {
+			return endpoint, false, true
+		}
+	}
+	if IsOpenAlexID(id) {
+		endpoint, ok := entityEndpoints[ShortID(id)[0]]
+		return endpoint, true, ok
+	}
+	return "", false, false
+}
+
+// selectFields returns the top-level JSON member names of struct type t,
+// sorted, for use with Select, which only accepts root fields. It returns nil,
+// selecting every field, for the models and for types other than structs.
+func selectFields(t reflect.Type) []string {
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	if _, ok := extraField(t); ok {
+		return nil
+	}
+	roots := make(map[string]bool)
+	rootFields(t, roots)
+	return slices.Sorted(maps.Keys(roots))
+}
+
+// rootFields adds the JSON member names of struct type t to roots, following
+// encoding/json in promoting the members of untagged embedded structs.
+func rootFields(t reflect.Type, roots map[string]bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			rootFields(ft, roots)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		roots[name] = true
+	}
+}
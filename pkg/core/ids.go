@@ -0,0 +1,38 @@
+package core
+
+import (
+	"regexp"
+	"strings"
+)
+
+// FilterOpenAlexID is the filter that looks entities up by OpenAlex ID. Up to
+// 100 IDs, in short or URL form, can be joined with "|" in one value.
+const FilterOpenAlexID = "openalex"
+
+// openAlexIDPattern matches native OpenAlex IDs such as W2741809807 or A5023888391.
+var openAlexIDPattern = regexp.MustCompile(`^[WAISTKPFCwaistkpfc]\d+$`)
+
+// ShortID strips the https://openalex.org/ prefix from an OpenAlex ID and
+// upper-cases the entity letter, so "https://openalex.org/w123" becomes "W123".
+// Other identifiers, such as DOIs or ORCIDs, are returned unchanged.
+func ShortID(id string) string {
+	short := id
+	for _, prefix := range []string{"https://openalex.org/", "http://openalex.org/", "openalex.org/"} {
+		if rest, ok := strings.CutPrefix(id, prefix); ok {
+			short = rest
+			break
+		}
+	}
+	if !IsOpenAlexID(short) {
+		return id
+	}
+	return strings.ToUpper(short[:1]) + short[1:]
+}
+
+// IsOpenAlexID reports whether id is a native OpenAlex ID, in short or URL form.
+func IsOpenAlexID(id string) bool {
+	if i := strings.LastIndexByte(id, '/'); i >= 0 && strings.Contains(id, "openalex.org/") {
+		id = id[i+1:]
+	}
+	return openAlexIDPattern.MatchString(id)
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// redactedParams are query parameters whose values are hidden in logs.
+var redactedParams = []string{"api_key", "mailto"}
+
+// redactURL renders u with the values of credential parameters hidden.
+func redactURL(u *url.URL) string {
+	r := *u
+	q := r.Query()
+	for _, p := range redactedParams {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+		}
+	}
+	r.RawQuery = q.Encode()
+	return r.String()
+}
+
+// redactError renders err, hiding credentials in the URL that transport errors carry.
+func redactError(err error) string {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, perr := url.Parse(urlErr.URL); perr == nil {
+			return (&url.Error{Op: urlErr.Op, URL: redactURL(u), Err: urlErr.Err}).Error()
+		}
+	}
+	return err.Error()
+}
+
+// retryReason summarizes why a failed attempt is retried.
+func retryReason(err error) string {
+	var apiErr *APIError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &apiErr):
+		return fmt.Sprintf("status %d", apiErr.StatusCode)
+	case errors.As(err, &urlErr) && urlErr.Timeout():
+		return "timeout"
+	default:
+		return redactError(err)
+	}
+}
+
+// logAttempt logs the outcome of a request attempt when the client has a logger.
+func (c *Client) logAttempt(ctx context.Context, req *http.Request, attempt int, resp *http.Response, size int64, duration time.Duration, err error, retry bool) {
+	if c.Logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL)),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", duration),
+	}
+	if resp != nil {
+		attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	}
+	switch {
+	case err == nil:
+		attrs = append(attrs, slog.Int64("size", size))
+		c.Logger.LogAttrs(ctx, slog.LevelDebug, "openalex request", attrs...)
+	case retry:
+		attrs = append(attrs,
+			slog.String("retry_reason", retryReason(err)),
+			slog.Duration("retry_in", c.RetryDelay*time.Duration(attempt)))
+		c.Logger.LogAttrs(ctx, slog.LevelWarn, "openalex request failed, retrying", attrs...)
+	default:
+		attrs = append(attrs, slog.String("error", redactError(err)))
+		c.Logger.LogAttrs(ctx, slog.LevelError, "openalex request failed", attrs...)
+	}
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Doer performs a single HTTP request attempt.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc adapts a function to the Doer interface.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps the Doer that performs a request attempt. Middleware runs
+// once per attempt, retries included, and AttemptFromContext on the request
+// context reports which attempt it is.
+//
+// When next returns, the client has already interpreted the response: the
+// error is an *APIError for error statuses, a decode error when the body is
+// not valid JSON, or the transport error, and the body has been consumed.
+// Middleware that answers without calling next, for example to inject
+// failures, returns a response that the client interprets afterwards.
+type Middleware func(next Doer) Doer
+
+// WithMiddleware appends middleware to the client. The first middleware
+// registered is the outermost one.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.Middleware = append(c.Middleware, mw...)
+	}
+}
+
+type attemptKey struct{}
+
+// attempt tracks the state of a single request attempt.
+type attempt struct {
+	number  int
+	decoded bool
+	size    int64
+}
+
+// AttemptFromContext returns the number of the attempt a request belongs to,
+// starting at 1, or 0 when ctx does not come from a client request.
+func AttemptFromContext(ctx context.Context) int {
+	if a, ok := ctx.Value(attemptKey{}).(*attempt); ok {
+		return a.number
+	}
+	return 0
+}
+
+// decodeError reports a response body that could not be decoded.
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return fmt.Sprintf("failed to decode response: %v", e.err)
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// do runs one attempt through the middleware chain and decodes the response
+// into out. It also returns the number of body bytes read.
+func (c *Client) do(req *http.Request, number int, out any) (*http.Response, int64, error) {
+	a := &attempt{number: number}
+	var next Doer = DoerFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		a.decoded = true
+		a.size, err = c.decode(resp, out)
+		return resp, err
+	})
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		next = c.Middleware[i](next)
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), attemptKey{}, a))
+	resp, err := next.Do(req)
+	if err == nil && !a.decoded {
+		if resp == nil {
+			return nil, 0, fmt.Errorf("middleware returned neither a response nor an error")
+		}
+		a.size, err = c.decode(resp, out)
+	}
+	return resp, a.size, err
+}
+
+// decode checks the status of resp and decodes its body into out, closing it.
+// It returns the number of body bytes read.
+func (c *Client) decode(resp *http.Response, out any) (int64, error) {
+	if resp.Body == nil {
+		resp.Body = http.NoBody
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 400 {
+		return 0, &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	if !c.inspects(out) {
+		// Nothing needs the raw body afterwards, so it is decoded as it is read.
+		body := &countingReader{r: resp.Body}
+		err := json.NewDecoder(body).Decode(out)
+		// Drain the rest so that the connection can be reused.
+		_, _ = io.Copy(io.Discard, body)
+		if err != nil {
+			return body.n, &decodeError{err: err}
+		}
+		return body.n, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return int64(len(body)), &decodeError{err: err}
+	}
+	return int64(len(body)), c.decodeBody(body, out)
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// decodeBody decodes a response body into out and inspects it for fields the
+// models do not capture.
+func (c *Client) decodeBody(body []byte, out any) error {
+	if err := json.Unmarshal(body, out); err != nil {
+		return &decodeError{err: err}
+	}
+	if err := c.inspect(body, out); err != nil {
+		return &decodeError{err: err}
+	}
+	return nil
+}
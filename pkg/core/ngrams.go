@@ -0,0 +1,78 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Sunhill666/goalex/internal/model"
+)
+
+// ErrNGramsUnavailable is returned when OpenAlex has no n-grams for a work or
+// the n-gram service is unavailable. The underlying *APIError stays available
+// through errors.As.
+var ErrNGramsUnavailable = errors.New("n-grams unavailable")
+
+// unavailableError marks an API error as ErrNGramsUnavailable.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrNGramsUnavailable, e.err)
+}
+
+func (e *unavailableError) Is(target error) bool {
+	return target == ErrNGramsUnavailable
+}
+
+func (e *unavailableError) Unwrap() error {
+	return e.err
+}
+
+// NGrams retrieves the n-grams of the full text of a work. A work whose
+// n-grams are empty yields an empty slice; one that OpenAlex has no n-grams
+// for, or a failing n-gram service, yields an error matching
+// ErrNGramsUnavailable. N-grams only exist for works.
+func (q *QueryBuilder[T]) NGrams(ctx context.Context, id string) ([]*model.NGram, error) {
+	if q.endpoint != EndpointWorks {
+		return nil, fmt.Errorf("n-grams are only available for works, not %s", q.endpoint)
+	}
+	resp, err := getAs[model.NGramsResponse](ctx, q, "NGrams", ShortID(id)+"/ngrams")
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			switch apiErr.StatusCode {
+			case http.StatusNotFound, http.StatusNotImplemented, http.StatusServiceUnavailable:
+				return nil, &unavailableError{err: err}
+			}
+		}
+		return nil, err
+	}
+	if resp.NGrams == nil {
+		return []*model.NGram{}, nil
+	}
+	return resp.NGrams, nil
+}
+
+// WorkNGrams is the outcome of fetching the n-grams of one work in a batch.
+type WorkNGrams struct {
+	ID     string
+	NGrams []*model.NGram
+	Err    error
+}
+
+// NGramsBatch retrieves the n-grams of many works with bounded concurrency
+// within the client's rate limit, returning one result per ID in the order given. Failures, including
+// ErrNGramsUnavailable, are reported per work.
+func (q *QueryBuilder[T]) NGramsBatch(ctx context.Context, ids []string, opts BatchOptions) []*WorkNGrams {
+	results := make([]*WorkNGrams, len(ids))
+	batch(ctx, len(ids), q.client.limiter, opts, func(ctx context.Context, i int) {
+		ngrams, err := q.NGrams(ctx, ids[i])
+		results[i] = &WorkNGrams{ID: ids[i], NGrams: ngrams, Err: err}
+	}, func(i int, err error) {
+		results[i] = &WorkNGrams{ID: ids[i], Err: err}
+	})
+	return results
+}
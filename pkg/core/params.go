@@ -39,6 +39,7 @@ type QueryParams struct {
 	Sample       int
 	Seed         int
 	GroupBy      string
+	GroupBys     []string
 	AutoComplete string
 	Cursor       string
 }
@@ -93,6 +94,9 @@ func (q *QueryParams) ToQuery() url.Values {
 	if q.GroupBy != "" {
 		query.Set("group_by", q.GroupBy)
 	}
+	if len(q.GroupBys) > 0 {
+		query.Set("group_bys", strings.Join(q.GroupBys, ","))
+	}
 	if q.AutoComplete != "" {
 		query.Set("q", q.AutoComplete)
 	}
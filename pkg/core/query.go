@@ -15,7 +15,21 @@ const (
 	EndpointPublishers   = "/publishers"
 	EndpointFunders      = "/funders"
 	EndpointConcepts     = "/concepts"
+	EndpointDomains      = "/domains"
+	EndpointFields       = "/fields"
+	EndpointSubfields    = "/subfields"
+
+	EndpointCountries        = "/countries"
+	EndpointContinents       = "/continents"
+	EndpointSDGs             = "/sdgs"
+	EndpointLanguages        = "/languages"
+	EndpointLicenses         = "/licenses"
+	EndpointWorkTypes        = "/work-types"
+	EndpointSourceTypes      = "/source-types"
+	EndpointInstitutionTypes = "/institution-types"
+
 	EndPointAutoComplete = "/autocomplete"
+	EndpointText         = "/text"
 )
 
 // Query creates a new QueryBuilder for the specified endpoint and entity type.
@@ -71,3 +85,58 @@ func (c *Client) Funders() *QueryBuilder[model.Funder] {
 func (c *Client) Concepts() *QueryBuilder[model.Concept] {
 	return Query[model.Concept](c, EndpointConcepts)
 }
+
+// Domains returns a QueryBuilder for querying domains, the top level of the topic hierarchy.
+func (c *Client) Domains() *QueryBuilder[model.Domain] {
+	return Query[model.Domain](c, EndpointDomains)
+}
+
+// Fields returns a QueryBuilder for querying fields, the second level of the topic hierarchy.
+func (c *Client) Fields() *QueryBuilder[model.Field] {
+	return Query[model.Field](c, EndpointFields)
+}
+
+// Subfields returns a QueryBuilder for querying subfields, the third level of the topic hierarchy.
+func (c *Client) Subfields() *QueryBuilder[model.Subfield] {
+	return Query[model.Subfield](c, EndpointSubfields)
+}
+
+// Countries returns a QueryBuilder for querying countries.
+func (c *Client) Countries() *QueryBuilder[model.Country] {
+	return Query[model.Country](c, EndpointCountries)
+}
+
+// Continents returns a QueryBuilder for querying continents.
+func (c *Client) Continents() *QueryBuilder[model.Continent] {
+	return Query[model.Continent](c, EndpointContinents)
+}
+
+// SDGs returns a QueryBuilder for querying the UN Sustainable Development Goals.
+func (c *Client) SDGs() *QueryBuilder[model.SDG] {
+	return Query[model.SDG](c, EndpointSDGs)
+}
+
+// Languages returns a QueryBuilder for querying languages.
+func (c *Client) Languages() *QueryBuilder[model.Language] {
+	return Query[model.Language](c, EndpointLanguages)
+}
+
+// Licenses returns a QueryBuilder for querying licenses.
+func (c *Client) Licenses() *QueryBuilder[model.License] {
+	return Query[model.License](c, EndpointLicenses)
+}
+
+// WorkTypes returns a QueryBuilder for querying work types.
+func (c *Client) WorkTypes() *QueryBuilder[model.WorkType] {
+	return Query[model.WorkType](c, EndpointWorkTypes)
+}
+
+// SourceTypes returns a QueryBuilder for querying source types.
+func (c *Client) SourceTypes() *QueryBuilder[model.SourceType] {
+	return Query[model.SourceType](c, EndpointSourceTypes)
+}
+
+// InstitutionTypes returns a QueryBuilder for querying institution types.
+func (c *Client) InstitutionTypes() *QueryBuilder[model.InstitutionType] {
+	return Query[model.InstitutionType](c, EndpointInstitutionTypes)
+}
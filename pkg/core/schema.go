@@ -0,0 +1,292 @@
+package core
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// UnknownFields lists the fields of one decoded entity that its model does
+// not capture.
+type UnknownFields struct {
+	// Entity is the model type, such as Work, or "response" for the envelope
+	// of list responses.
+	Entity string
+	// Fields are sorted dotted paths relative to the entity, such as
+	// authorships.institution_assertions.
+	Fields []string
+}
+
+// UnknownFieldsError is returned in strict decoding mode when a response has
+// fields the models do not capture.
+type UnknownFieldsError struct {
+	Entities []UnknownFields
+}
+
+func (e *UnknownFieldsError) Error() string {
+	fields := make(map[string][]string)
+	var entities []string
+	for _, u := range e.Entities {
+		if _, ok := fields[u.Entity]; !ok {
+			entities = append(entities, u.Entity)
+		}
+		for _, f := range u.Fields {
+			if !slices.Contains(fields[u.Entity], f) {
+				fields[u.Entity] = append(fields[u.Entity], f)
+			}
+		}
+	}
+	parts := make([]string, len(entities))
+	for i, entity := range entities {
+		slices.Sort(fields[entity])
+		parts[i] = entity + ": " + strings.Join(fields[entity], ", ")
+	}
+	return "unknown fields in " + strings.Join(parts, "; ")
+}
+
+// WithStrictDecoding configures the client to fail with an *UnknownFieldsError
+// when a response has fields the models do not capture, so that additions to
+// the OpenAlex schema are noticed. The error is not retried.
+func WithStrictDecoding() Option {
+	return func(c *Client) {
+		c.strict = true
+	}
+}
+
+// WithUnknownFieldHandler configures the client to call fn for every decoded
+// entity that has fields its model does not capture.
+func WithUnknownFieldHandler(fn func(UnknownFields)) Option {
+	return func(c *Client) {
+		c.onUnknown = fn
+	}
+}
+
+// WithExtraFields configures the client to keep the top-level fields of each
+// decoded entity that its model does not capture in the entity's Extra map.
+// Extra is also filled in strict mode and when an unknown field handler is
+// set; otherwise responses are decoded without looking for such fields.
+func WithExtraFields() Option {
+	return func(c *Client) {
+		c.extra = true
+	}
+}
+
+// DiffSchema decodes data into v and reports the fields that v does not
+// capture, once per decoded entity.
+func DiffSchema(data []byte, v any) ([]UnknownFields, error) {
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+	w := &schemaWalker{deep: true}
+	w.walk(data, reflect.ValueOf(v))
+	return w.found, nil
+}
+
+// UnmarshalWithExtra decodes data into v like json.Unmarshal and keeps the
+// top-level fields of each model within v that the model does not capture in
+// its Extra map, as WithExtraFields does for responses. It suits data such as
+// entities encoded with their Extra fields; unlike DiffSchema, it does not look
+// for unknown fields below the top level of each model.
+func UnmarshalWithExtra(data []byte, v any) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+	if holdsEntity(reflect.TypeOf(v)) {
+		(&schemaWalker{}).walk(data, reflect.ValueOf(v))
+	}
+	return nil
+}
+
+// inspect captures the unknown fields of the decoded response data in the
+// Extra field of the models within out, and reports them as configured.
+func (c *Client) inspect(data []byte, out any) error {
+	if !c.inspects(out) {
+		return nil
+	}
+	w := &schemaWalker{deep: c.strict || c.onUnknown != nil}
+	w.walk(data, reflect.ValueOf(out))
+	if c.onUnknown != nil {
+		for _, u := range w.found {
+			c.onUnknown(u)
+		}
+	}
+	if c.strict && len(w.found) > 0 {
+		return &UnknownFieldsError{Entities: w.found}
+	}
+	return nil
+}
+
+// inspects reports whether responses decoded into out are inspected. Only the
+// models carry Extra and are checked; raw messages and caller-supplied types
+// are left alone.
+func (c *Client) inspects(out any) bool {
+	return (c.extra || c.strict || c.onUnknown != nil) && holdsEntity(reflect.TypeOf(out))
+}
+
+// schemaWalker walks a JSON document alongside the value it was decoded into.
+type schemaWalker struct {
+	// deep reports unknown fields at every level rather than only filling
+	// in Extra, which only needs the top level of each entity.
+	deep  bool
+	found []UnknownFields
+}
+
+// scope collects the unknown fields of the entity being walked.
+type scope struct {
+	entity string
+	fields []string
+}
+
+func (w *schemaWalker) walk(data []byte, v reflect.Value) {
+	root := &scope{entity: "response"}
+	w.value(data, v, root, "")
+	w.report(root)
+}
+
+func (w *schemaWalker) report(s *scope) {
+	if len(s.fields) == 0 {
+		return
+	}
+	slices.Sort(s.fields)
+	w.found = append(w.found, UnknownFields{Entity: s.entity, Fields: slices.Compact(s.fields)})
+}
+
+func (w *schemaWalker) value(data []byte, v reflect.Value, s *scope, path string) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Type() == reflect.TypeFor[json.RawMessage]() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if !w.deep && !holdsEntity(v.Type()) {
+			return
+		}
+		var items []json.RawMessage
+		if json.Unmarshal(data, &items) != nil {
+			return
+		}
+		for i := range min(len(items), v.Len()) {
+			w.value(items[i], v.Index(i), s, path)
+		}
+	case reflect.Struct:
+		w.object(data, v, s, path)
+	}
+}
+
+func (w *schemaWalker) object(data []byte, v reflect.Value, s *scope, path string) {
+	t := v.Type()
+	if !w.deep && !holdsEntity(t) {
+		return
+	}
+	var members map[string]json.RawMessage
+	if json.Unmarshal(data, &members) != nil {
+		return
+	}
+	fields := jsonFields(t)
+	extra, isEntity := extraField(t)
+	if isEntity {
+		s, path = &scope{entity: t.Name()}, ""
+		defer w.report(s)
+	}
+	var unknown map[string]json.RawMessage
+	for name, member := range members {
+		index, ok := fields[name]
+		if !ok {
+			if unknown == nil {
+				unknown = make(map[string]json.RawMessage)
+			}
+			unknown[name] = member
+			s.fields = append(s.fields, path+name)
+			continue
+		}
+		if f, err := v.FieldByIndexErr(index); err == nil {
+			w.value(member, f, s, path+name+".")
+		}
+	}
+	if isEntity && v.CanSet() {
+		v.Field(extra).Set(reflect.ValueOf(unknown))
+	}
+	if !w.deep {
+		s.fields = nil
+	}
+}
+
+var (
+	jsonFieldCache sync.Map // reflect.Type -> map[string][]int
+	holdsCache     sync.Map // reflect.Type -> bool
+)
+
+// jsonFields maps the JSON member names of struct type t to field indexes.
+func jsonFields(t reflect.Type) map[string][]int {
+	if cached, ok := jsonFieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		// Shallower fields win, matching encoding/json.
+		if existing, ok := fields[name]; ok && len(existing) <= len(f.Index) {
+			continue
+		}
+		fields[name] = f.Index
+	}
+	jsonFieldCache.Store(t, fields)
+	return fields
+}
+
+// extraField returns the index of the Extra field declared directly on struct
+// type t, which marks t as an entity model.
+func extraField(t reflect.Type) (int, bool) {
+	f, ok := t.FieldByName("Extra")
+	if !ok || len(f.Index) != 1 || f.Type != reflect.TypeFor[map[string]json.RawMessage]() {
+		return 0, false
+	}
+	return f.Index[0], true
+}
+
+// holdsEntity reports whether values of type t can contain an entity model.
+func holdsEntity(t reflect.Type) bool {
+	if cached, ok := holdsCache.Load(t); ok {
+		return cached.(bool)
+	}
+	holds := searchEntity(t, map[reflect.Type]bool{})
+	holdsCache.Store(t, holds)
+	return holds
+}
+
+func searchEntity(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return searchEntity(t.Elem(), visiting)
+	case reflect.Struct:
+		if _, ok := extraField(t); ok {
+			return true
+		}
+		for _, index := range jsonFields(t) {
+			if searchEntity(t.FieldByIndex(index).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Sunhill666/goalex/internal/model"
+)
+
+// Attribute is a key-value pair attached to spans and measurements. Values are
+// strings, bools, ints, float64s or string slices, which map directly onto
+// OpenTelemetry attribute types.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Tracer starts spans for client operations. It is small enough to be
+// implemented by a thin adapter over an OpenTelemetry tracer.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is an operation started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Meter records client metrics. Add increments a counter and Record adds a
+// value to a histogram.
+type Meter interface {
+	Add(ctx context.Context, name string, delta int64, attrs ...Attribute)
+	Record(ctx context.Context, name string, value float64, attrs ...Attribute)
+}
+
+// Metric names recorded through a Meter.
+const (
+	// MetricRequests counts request attempts, retries included.
+	MetricRequests = "goalex.client.requests"
+	// MetricRetries counts attempts that failed and were retried.
+	MetricRetries = "goalex.client.retries"
+	// MetricDuration records the duration of request attempts in seconds.
+	MetricDuration = "goalex.client.request.duration"
+)
+
+// Attribute keys set on spans and measurements.
+const (
+	AttrEndpoint       = "goalex.endpoint"
+	AttrFilterKeys     = "goalex.filter_keys"
+	AttrSearch         = "goalex.search"
+	AttrGroupBy        = "goalex.group_by"
+	AttrCursorPage     = "goalex.cursor_page"
+	AttrResultCount    = "goalex.result_count"
+	AttrTotalCount     = "goalex.total_count"
+	AttrDBResponseTime = "goalex.db_response_time_ms"
+	AttrAttempt        = "goalex.attempt"
+	AttrRetryReason    = "goalex.retry_reason"
+	AttrStatusCode     = "http.response.status_code"
+	AttrErrorType      = "error.type"
+)
+
+// WithTracer configures the client to create a span for every builder operation.
+func WithTracer(t Tracer) Option {
+	return func(c *Client) {
+		c.Tracer = t
+	}
+}
+
+// WithMeter configures the client to record request metrics.
+func WithMeter(m Meter) Option {
+	return func(c *Client) {
+		c.Meter = m
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// startSpan starts a span when the client has a tracer.
+func (c *Client) startSpan(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	if c.Tracer == nil {
+		return ctx, noopSpan{}
+	}
+	return c.Tracer.Start(ctx, name, attrs...)
+}
+
+// endSpan records the outcome of an operation and ends its span.
+func endSpan(span Span, results int, meta *model.PaginatedResponseMeta, err error) {
+	if err != nil {
+		span.RecordError(err)
+	} else {
+		span.SetAttributes(Attribute{AttrResultCount, results})
+	}
+	if meta != nil {
+		span.SetAttributes(Attribute{AttrTotalCount, meta.Count}, Attribute{AttrDBResponseTime, meta.DBRespTime})
+	}
+	span.End()
+}
+
+// recordAttempt records the metrics of a request attempt when the client has a meter.
+func (c *Client) recordAttempt(ctx context.Context, u *url.URL, status int, duration time.Duration, err error, retry bool) {
+	if c.Meter == nil {
+		return
+	}
+	endpoint := endpointOf(c.relativePath(u))
+	attrs := []Attribute{{AttrEndpoint, endpoint}}
+	if status > 0 {
+		attrs = append(attrs, Attribute{AttrStatusCode, status})
+	}
+	if err != nil {
+		attrs = append(attrs, Attribute{AttrErrorType, errorType(err)})
+	}
+	c.Meter.Add(ctx, MetricRequests, 1, attrs...)
+	c.Meter.Record(ctx, MetricDuration, duration.Seconds(), attrs...)
+	if retry {
+		c.Meter.Add(ctx, MetricRetries, 1, Attribute{AttrEndpoint, endpoint}, Attribute{AttrRetryReason, retryReason(err)})
+	}
+}
+
+// relativePath returns the path of u below the path of the client's base URL,
+// such as /works for https://example.org/openalex/works when the base URL is
+// https://example.org/openalex.
+func (c *Client) relativePath(u *url.URL) string {
+	base, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return u.Path
+	}
+	return strings.TrimPrefix(u.Path, strings.TrimSuffix(base.Path, "/"))
+}
+
+// subResources are the resources of a single entity, such as /works/{id}/ngrams.
+var subResources = []string{"ngrams"}
+
+// endpointOf reduces a request path to a low-cardinality endpoint such as
+// /works, /works/{id}, /works/{id}/ngrams or /autocomplete/authors. IDs such
+// as DOIs may contain slashes, so only a known sub-resource is kept after one.
+func endpointOf(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	last := parts[len(parts)-1]
+	switch {
+	case parts[0] == "":
+		return "/"
+	case parts[0] == "autocomplete" && len(parts) > 1:
+		return "/autocomplete/" + parts[1]
+	case len(parts) > 2 && slices.Contains(subResources, last):
+		return "/" + parts[0] + "/{id}/" + last
+	case len(parts) > 1:
+		return "/" + parts[0] + "/{id}"
+	default:
+		return "/" + parts[0]
+	}
+}
+
+func errorType(err error) string {
+	var apiErr *APIError
+	var decErr *decodeError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &apiErr):
+		return "http"
+	case errors.As(err, &decErr):
+		return "decode"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &urlErr) && urlErr.Timeout():
+		return "timeout"
+	default:
+		return "transport"
+	}
+}
+
+// operation names the span of a builder operation, such as Works.List or
+// Authors.AutoComplete.
+func operation(endpoint, op string) string {
+	parts := strings.Split(strings.Trim(endpoint, "/"), "/")
+	if parts[0] == "autocomplete" && len(parts) > 1 {
+		return title(parts[1]) + ".AutoComplete"
+	}
+	return title(parts[0]) + "." + op
+}
+
+// title turns an endpoint name such as works or work-types into Works or WorkTypes.
+func title(s string) string {
+	var b strings.Builder
+	for part := range strings.SplitSeq(s, "-") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// spanAttributes describes the query parameters of a builder operation.
+func spanAttributes(endpoint string, params *QueryParams) []Attribute {
+	attrs := []Attribute{{AttrEndpoint, endpoint}}
+	if params == nil {
+		return attrs
+	}
+	if len(params.Filter) > 0 {
+		keys := make([]string, 0, len(params.Filter))
+		for k := range params.Filter {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		attrs = append(attrs, Attribute{AttrFilterKeys, keys})
+	}
+	if params.Search != "" {
+		attrs = append(attrs, Attribute{AttrSearch, true})
+	}
+	if params.GroupBy != "" {
+		attrs = append(attrs, Attribute{AttrGroupBy, params.GroupBy})
+	}
+	if len(params.GroupBys) > 0 {
+		attrs = append(attrs, Attribute{AttrGroupBy, strings.Join(params.GroupBys, ",")})
+	}
+	return attrs
+}
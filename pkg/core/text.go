@@ -0,0 +1,70 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/Sunhill666/goalex/internal/model"
+)
+
+// maxTextQueryLength is the longest encoded title and abstract sent in a GET
+// query string; longer texts are sent as a POST body.
+const maxTextQueryLength = 1500
+
+// TextDocument is a title and abstract to classify.
+type TextDocument struct {
+	Title    string `json:"title,omitempty"`
+	Abstract string `json:"abstract,omitempty"`
+}
+
+// ClassifyText tags a title and abstract with the topics, keywords and
+// concepts OpenAlex would assign to a work with that text. Short texts are
+// sent as a GET query and long ones as a POST body.
+func (c *Client) ClassifyText(ctx context.Context, title, abstract string) (*model.TextClassification, error) {
+	if title == "" && abstract == "" {
+		return nil, errors.New("classify text: title or abstract is required")
+	}
+	ctx, span := c.startSpan(ctx, operation(EndpointText, "Classify"), Attribute{AttrEndpoint, EndpointText})
+
+	q := url.Values{}
+	if title != "" {
+		q.Set("title", title)
+	}
+	if abstract != "" {
+		q.Set("abstract", abstract)
+	}
+	var result model.TextClassification
+	var err error
+	if query := q.Encode(); len(query) <= maxTextQueryLength {
+		_, err = c.get(ctx, EndpointText+"?"+query, &result)
+	} else {
+		_, err = c.post(ctx, EndpointText, TextDocument{Title: title, Abstract: abstract}, &result)
+	}
+	endSpan(span, len(result.Topics), nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// TextClassificationResult is the outcome of classifying one document in a batch.
+type TextClassificationResult struct {
+	Document       TextDocument
+	Classification *model.TextClassification
+	Err            error
+}
+
+// ClassifyTextBatch classifies many documents with bounded concurrency
+// within the client's rate limit, returning one result per document in the order given. Failures are
+// reported per document.
+func (c *Client) ClassifyTextBatch(ctx context.Context, docs []TextDocument, opts BatchOptions) []*TextClassificationResult {
+	results := make([]*TextClassificationResult, len(docs))
+	batch(ctx, len(docs), c.limiter, opts, func(ctx context.Context, i int) {
+		classification, err := c.ClassifyText(ctx, docs[i].Title, docs[i].Abstract)
+		results[i] = &TextClassificationResult{Document: docs[i], Classification: classification, Err: err}
+	}, func(i int, err error) {
+		results[i] = &TextClassificationResult{Document: docs[i], Err: err}
+	})
+	return results
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/Sunhill666/goalex/internal/model"
+)
+
+// TopicTree is the four-level OpenAlex topic hierarchy of domains, fields,
+// subfields and topics, held in memory for navigation.
+type TopicTree struct {
+	// Domains are the roots of the tree, sorted by display name.
+	Domains []*DomainNode
+
+	domains   map[string]*DomainNode
+	fields    map[string]*FieldNode
+	subfields map[string]*SubfieldNode
+	topics    map[string]*TopicNode
+}
+
+// DomainNode is a domain and its fields, sorted by display name. Fields
+// shadows the field references of the model, which remain available as
+// Domain.Fields; the other nodes follow the same pattern.
+type DomainNode struct {
+	*model.Domain
+	Fields []*FieldNode
+}
+
+// FieldNode is a field, its parent domain and its subfields, sorted by display name.
+type FieldNode struct {
+	*model.Field
+	Parent    *DomainNode
+	Subfields []*SubfieldNode
+}
+
+// SubfieldNode is a subfield, its parent field and its topics, sorted by display name.
+type SubfieldNode struct {
+	*model.Subfield
+	Parent *FieldNode
+	Topics []*TopicNode
+}
+
+// TopicNode is a topic and its parent subfield.
+type TopicNode struct {
+	*model.Topic
+	Parent *SubfieldNode
+}
+
+// LoadTopicTree fetches every domain, field, subfield and topic and links them
+// into a TopicTree. It takes a few dozen requests, most of them for the
+// several thousand topics.
+func LoadTopicTree(ctx context.Context, c *Client) (*TopicTree, error) {
+	domains, err := collect(ctx, c.Domains())
+	if err != nil {
+		return nil, fmt.Errorf("load domains: %w", err)
+	}
+	fields, err := collect(ctx, c.Fields())
+	if err != nil {
+		return nil, fmt.Errorf("load fields: %w", err)
+	}
+	subfields, err := collect(ctx, c.Subfields())
+	if err != nil {
+		return nil, fmt.Errorf("load subfields: %w", err)
+	}
+	topics, err := collect(ctx, c.Topics())
+	if err != nil {
+		return nil, fmt.Errorf("load topics: %w", err)
+	}
+	return NewTopicTree(domains, fields, subfields, topics), nil
+}
+
+// NewTopicTree links already fetched entities into a TopicTree. Entities whose
+// parent is missing are left out.
+func NewTopicTree(domains []*model.Domain, fields []*model.Field, subfields []*model.Subfield, topics []*model.Topic) *TopicTree {
+	t := &TopicTree{
+		domains:   make(map[string]*DomainNode, len(domains)),
+		fields:    make(map[string]*FieldNode, len(fields)),
+		subfields: make(map[string]*SubfieldNode, len(subfields)),
+		topics:    make(map[string]*TopicNode, len(topics)),
+	}
+	for _, d := range domains {
+		node := &DomainNode{Domain: d}
+		t.domains[treeKey(d.ID)] = node
+		t.Domains = append(t.Domains, node)
+	}
+	for _, f := range fields {
+		if f.Domain == nil || t.domains[treeKey(f.Domain.ID)] == nil {
+			continue
+		}
+		parent := t.domains[treeKey(f.Domain.ID)]
+		node := &FieldNode{Field: f, Parent: parent}
+		t.fields[treeKey(f.ID)] = node
+		parent.Fields = append(parent.Fields, node)
+	}
+	for _, s := range subfields {
+		if s.Field == nil || t.fields[treeKey(s.Field.ID)] == nil {
+			continue
+		}
+		parent := t.fields[treeKey(s.Field.ID)]
+		node := &SubfieldNode{Subfield: s, Parent: parent}
+		t.subfields[treeKey(s.ID)] = node
+		parent.Subfields = append(parent.Subfields, node)
+	}
+	for _, topic := range topics {
+		if topic.Subfield == nil || t.subfields[treeKey(topic.Subfield.ID)] == nil {
+			continue
+		}
+		parent := t.subfields[treeKey(topic.Subfield.ID)]
+		node := &TopicNode{Topic: topic, Parent: parent}
+		t.topics[treeKey(topic.ID)] = node
+		parent.Topics = append(parent.Topics, node)
+	}
+
+	sortByName(t.Domains, func(n *DomainNode) string { return n.DisplayName })
+	for _, d := range t.Domains {
+		sortByName(d.Fields, func(n *FieldNode) string { return n.DisplayName })
+		for _, f := range d.Fields {
+			sortByName(f.Subfields, func(n *SubfieldNode) string { return n.DisplayName })
+			for _, s := range f.Subfields {
+				sortByName(s.Topics, func(n *TopicNode) string { return n.DisplayName })
+			}
+		}
+	}
+	return t
+}
+
+// Domain returns the domain with the given ID, such as
+// https://openalex.org/domains/1 or 1, or nil if the tree has none.
+func (t *TopicTree) Domain(id string) *DomainNode {
+	return t.domains[treeKey(id)]
+}
+
+// Field returns the field with the given ID, such as
+// https://openalex.org/fields/17 or 17, or nil if the tree has none.
+func (t *TopicTree) Field(id string) *FieldNode {
+	return t.fields[treeKey(id)]
+}
+
+// Subfield returns the subfield with the given ID, such as
+// https://openalex.org/subfields/1702 or 1702, or nil if the tree has none.
+func (t *TopicTree) Subfield(id string) *SubfieldNode {
+	return t.subfields[treeKey(id)]
+}
+
+// Topic returns the topic with the given ID, such as
+// https://openalex.org/T10001 or T10001, or nil if the tree has none.
+func (t *TopicTree) Topic(id string) *TopicNode {
+	return t.topics[treeKey(id)]
+}
+
+// Len returns the number of topics in the tree.
+func (t *TopicTree) Len() int {
+	return len(t.topics)
+}
+
+// Path returns the display names from the domain down to the topic, or nil if
+// the tree has no topic with the given ID.
+func (t *TopicTree) Path(topicID string) []string {
+	topic := t.Topic(topicID)
+	if topic == nil {
+		return nil
+	}
+	subfield := topic.Parent
+	field := subfield.Parent
+	return []string{field.Parent.DisplayName, field.DisplayName, subfield.DisplayName, topic.DisplayName}
+}
+
+// treeKey reduces an ID of the topic hierarchy to its last path segment, with
+// topic IDs in canonical case.
+func treeKey(id string) string {
+	id = ShortID(id)
+	if i := strings.LastIndexByte(id, '/'); i >= 0 {
+		id = id[i+1:]
+	}
+	return id
+}
+
+func sortByName[N any](nodes []N, name func(N) string) {
+	slices.SortStableFunc(nodes, func(a, b N) int { return cmp.Compare(name(a), name(b)) })
+}
+
+// collect fetches every entity of a query with the largest page size.
+func collect[T any](ctx context.Context, q *QueryBuilder[T]) ([]*T, error) {
+	var all []*T
+	for entity, err := range q.PerPage(200).All(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, entity)
+	}
+	return all, nil
+}
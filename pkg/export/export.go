@@ -0,0 +1,293 @@
+// Package export writes OpenAlex entities to flat tabular files (CSV, TSV) and
+// JSON Lines, flattening nested fields with dotted column paths.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// Format identifies an output file format.
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatTSV   Format = "tsv"
+	FormatJSONL Format = "jsonl"
+	FormatTable Format = "table"
+)
+
+// DefaultSeparator joins multiple values resolved by a single column, e.g. "authorships[*].author.display_name".
+const DefaultSeparator = "; "
+
+// ParseFormat converts a format name such as "csv" or "jsonl" into a Format.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case FormatCSV, FormatTSV, FormatJSONL, FormatTable:
+		return f, nil
+	case "ndjson":
+		return FormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q", name)
+	}
+}
+
+// Column is a named output column backed by a dotted path into the entity.
+type Column struct {
+	Name string
+	Path *Path
+}
+
+// ParseColumns compiles column specs. A spec is either a bare path, used as its
+// own header, or "header=path".
+func ParseColumns(specs ...string) ([]Column, error) {
+	columns := make([]Column, 0, len(specs))
+	for _, spec := range specs {
+		name, raw, ok := strings.Cut(spec, "=")
+		if !ok {
+			raw = spec
+		}
+		name, raw = strings.TrimSpace(name), strings.TrimSpace(raw)
+		path, err := ParsePath(raw)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, Column{Name: name, Path: path})
+	}
+	return columns, nil
+}
+
+// Roots returns the distinct top-level fields referenced by the columns.
+func Roots(columns []Column) []string {
+	seen := make(map[string]bool)
+	var roots []string
+	for _, c := range columns {
+		if root := c.Path.Root(); !seen[root] {
+			seen[root] = true
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// Writer streams records in a single format.
+type Writer struct {
+	format    Format
+	columns   []Column
+	separator string
+	csv       *csv.Writer
+	table     *tabwriter.Writer
+	json      *json.Encoder
+	header    bool
+}
+
+// NewWriter creates a Writer. CSV, TSV and table output require at least one
+// column; JSONL without columns writes whole records.
+func NewWriter(w io.Writer, format Format, columns []Column) (*Writer, error) {
+	ew := &Writer{format: format, columns: columns, separator: DefaultSeparator}
+	switch format {
+	case FormatCSV, FormatTSV:
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("%s export requires at least one column", format)
+		}
+		ew.csv = csv.NewWriter(w)
+		if format == FormatTSV {
+			ew.csv.Comma = '\t'
+		}
+	case FormatTable:
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("%s output requires at least one column", format)
+		}
+		ew.table = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	case FormatJSONL:
+		ew.json = json.NewEncoder(w)
+		ew.json.SetEscapeHTML(false)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+	return ew, nil
+}
+
+// SetSeparator sets the string used to join multiple values in a CSV or TSV cell.
+func (w *Writer) SetSeparator(sep string) {
+	w.separator = sep
+}
+
+// Write writes one record.
+func (w *Writer) Write(record any) error {
+	if w.json != nil {
+		return w.writeJSON(record)
+	}
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		values, err := c.Path.Resolve(record)
+		if err != nil {
+			return err
+		}
+		cells := make([]string, 0, len(values))
+		for _, v := range values {
+			s, err := formatValue(v)
+			if err != nil {
+				return fmt.Errorf("column %q: %w", c.Name, err)
+			}
+			cells = append(cells, s)
+		}
+		row[i] = strings.Join(cells, w.separator)
+	}
+	return w.writeRow(row)
+}
+
+// writeHeader writes the header row of CSV, TSV and table output unless it
+// has been written already.
+func (w *Writer) writeHeader() error {
+	if w.header || w.json != nil {
+		return nil
+	}
+	header := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		header[i] = c.Name
+	}
+	if err := w.writeRow(header); err != nil {
+		return err
+	}
+	w.header = true
+	return nil
+}
+
+func (w *Writer) writeRow(row []string) error {
+	if w.table != nil {
+		for i, cell := range row {
+			// Tabs and newlines would break the alignment.
+			row[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell)
+		}
+		_, err := fmt.Fprintln(w.table, strings.Join(row, "\t"))
+		return err
+	}
+	return w.csv.Write(row)
+}
+
+func (w *Writer) writeJSON(record any) error {
+	if len(w.columns) == 0 {
+		return w.json.Encode(record)
+	}
+	obj := make(map[string]any, len(w.columns))
+	for _, c := range w.columns {
+		values, err := c.Path.Resolve(record)
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(values) == 0:
+			obj[c.Name] = nil
+		case len(values) == 1 && !c.Path.expands():
+			obj[c.Name] = values[0].Interface()
+		default:
+			list := make([]any, len(values))
+			for i, v := range values {
+				list[i] = v.Interface()
+			}
+			obj[c.Name] = list
+		}
+	}
+	return w.json.Encode(obj)
+}
+
+// Flush writes any buffered data to the underlying writer, starting with the
+// header if no record has been written.
+func (w *Writer) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	if w.table != nil {
+		return w.table.Flush()
+	}
+	if w.csv != nil {
+		w.csv.Flush()
+		return w.csv.Error()
+	}
+	return nil
+}
+
+// Options configures Query.
+type Options struct {
+	Format  Format
+	Columns []Column
+	// Separator joins multiple values in a CSV or TSV cell. Defaults to DefaultSeparator.
+	Separator string
+	// PerPage is the page size used while harvesting. Defaults to 200, the API maximum.
+	PerPage int
+	// Limit stops the export after this many records. Zero means no limit.
+	Limit int
+}
+
+// Query streams every result of q to w using cursor pagination and returns the
+// number of records written. When columns are given, the query is restricted
+// to the top-level fields they reference with Select, in addition to those q
+// already selects. q itself is not changed.
+func Query[T any](ctx context.Context, q *core.QueryBuilder[T], w io.Writer, opts Options) (int, error) {
+	q = q.Clone()
+	ew, err := NewWriter(w, opts.Format, opts.Columns)
+	if err != nil {
+		return 0, err
+	}
+	if opts.Separator != "" {
+		ew.SetSeparator(opts.Separator)
+	}
+	if len(opts.Columns) > 0 {
+		selected := q.Selected()
+		for _, root := range Roots(opts.Columns) {
+			if !slices.Contains(selected, root) {
+				q.Select(root)
+			}
+		}
+	}
+	perPage := opts.PerPage
+	if perPage <= 0 {
+		perPage = 200
+	}
+	if opts.Limit > 0 {
+		perPage = min(perPage, opts.Limit)
+	}
+	q.PerPage(perPage)
+
+	written := 0
+	cursor := "*"
+	for cursor != "" {
+		results, next, err := q.CursorWithContext(ctx, cursor)
+		if err != nil {
+			return written, err
+		}
+		for _, r := range results {
+			if err := ew.Write(r); err != nil {
+				return written, err
+			}
+			written++
+			if opts.Limit > 0 && written >= opts.Limit {
+				return written, ew.Flush()
+			}
+		}
+		// Flushing a table restarts its column alignment, so tables are
+		// flushed once at the end.
+		if opts.Format != FormatTable {
+			if err := ew.Flush(); err != nil {
+				return written, err
+			}
+		}
+		if len(results) == 0 {
+			break
+		}
+		cursor = next
+	}
+	return written, ew.Flush()
+}
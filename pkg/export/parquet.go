@@ -0,0 +1,392 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// DefaultRowGroupSize is the number of rows buffered before a row group is written.
+const DefaultRowGroupSize = 10000
+
+// ParquetOptions configures ParquetWriter and QueryParquet.
+type ParquetOptions struct {
+	// Compression names the codec: "snappy" (default), "gzip", "zstd", "lz4", "brotli" or "none".
+	Compression string
+	// RowGroupSize bounds the number of rows held in memory before a row group
+	// is flushed. Defaults to DefaultRowGroupSize.
+	RowGroupSize int
+	// PerPage is the page size used by QueryParquet while harvesting. Defaults to 200.
+	PerPage int
+	// Limit stops QueryParquet after this many records. Zero means no limit.
+	Limit int
+}
+
+func compressionCodec(name string) (compress.Codec, error) {
+	switch strings.ToLower(name) {
+	case "", "snappy":
+		return &parquet.Snappy, nil
+	case "gzip":
+		return &parquet.Gzip, nil
+	case "zstd":
+		return &parquet.Zstd, nil
+	case "lz4":
+		return &parquet.Lz4Raw, nil
+	case "brotli":
+		return &parquet.Brotli, nil
+	case "none", "uncompressed":
+		return &parquet.Uncompressed, nil
+	default:
+		return nil, fmt.Errorf("unsupported parquet compression %q", name)
+	}
+}
+
+type pqKind int
+
+const (
+	pqLeaf pqKind = iota
+	pqGroup
+	pqList
+	pqMap
+)
+
+// pqField mirrors a node of the parquet schema and knows how to shred Go
+// values into column values for it. Every field is optional, so nil pointers,
+// slices and maps are written as nulls.
+type pqField struct {
+	name     string
+	kind     pqKind
+	index    []int // struct field index within the parent group
+	leafKind reflect.Kind
+	column   int // leaf column index
+	children []*pqField
+	elem     *pqField // list element or map value
+	key      int      // map key column index
+	columns  []int    // every leaf column below this field
+}
+
+// ParquetSchema derives the nested parquet schema of T from its JSON field
+// names. Lists of structs become repeated groups and maps become MAP groups.
+func ParquetSchema[T any]() (*parquet.Schema, error) {
+	root, err := parquetRoot(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	return parquet.NewSchema(schemaName(reflect.TypeFor[T]()), root.node().(parquet.Group)), nil
+}
+
+func schemaName(t reflect.Type) string {
+	return strings.ToLower(t.Name())
+}
+
+func parquetRoot(t reflect.Type) (*pqField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("parquet export requires a struct type, got %s", t)
+	}
+	root, err := buildField("", t, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	column := 0
+	root.assignColumns(&column)
+	return root, nil
+}
+
+func buildField(name string, t reflect.Type, seen map[reflect.Type]bool) (*pqField, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	f := &pqField{name: name}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f.kind = pqLeaf
+		f.leafKind = t.Kind()
+	case reflect.Struct:
+		if seen[t] {
+			return nil, fmt.Errorf("recursive type %s cannot be written to parquet", t)
+		}
+		seen[t] = true
+		defer delete(seen, t)
+		f.kind = pqGroup
+		fields := fieldsOf(t)
+		names := make([]string, 0, len(fields))
+		for n := range fields {
+			names = append(names, n)
+		}
+		// parquet.Group orders its fields by name; the shredder must match.
+		slices.Sort(names)
+		for _, n := range names {
+			sf := t.FieldByIndex(fields[n])
+			child, err := buildField(n, sf.Type, seen)
+			if err != nil {
+				return nil, err
+			}
+			child.index = fields[n]
+			f.children = append(f.children, child)
+		}
+	case reflect.Slice, reflect.Array:
+		elem, err := buildField("element", t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		f.kind = pqList
+		f.elem = elem
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		value, err := buildField("value", t.Elem(), seen)
+		if err != nil {
+			return nil, err
+		}
+		f.kind = pqMap
+		f.elem = value
+	default:
+		return nil, fmt.Errorf("unsupported field type %s", t)
+	}
+	return f, nil
+}
+
+// assignColumns numbers leaves in the depth-first order used by parquet.
+func (f *pqField) assignColumns(next *int) {
+	switch f.kind {
+	case pqLeaf:
+		f.column = *next
+		*next++
+		f.columns = []int{f.column}
+	case pqGroup:
+		for _, c := range f.children {
+			c.assignColumns(next)
+			f.columns = append(f.columns, c.columns...)
+		}
+	case pqList:
+		f.elem.assignColumns(next)
+		f.columns = f.elem.columns
+	case pqMap:
+		f.key = *next
+		*next++
+		f.elem.assignColumns(next)
+		f.columns = append([]int{f.key}, f.elem.columns...)
+	}
+}
+
+func (f *pqField) node() parquet.Node {
+	switch f.kind {
+	case pqGroup:
+		group := parquet.Group{}
+		for _, c := range f.children {
+			group[c.name] = parquet.Optional(c.node())
+		}
+		return group
+	case pqList:
+		return parquet.List(parquet.Optional(f.elem.node()))
+	case pqMap:
+		return parquet.Map(parquet.String(), parquet.Optional(f.elem.node()))
+	}
+	switch f.leafKind {
+	case reflect.String:
+		return parquet.String()
+	case reflect.Bool:
+		return parquet.Leaf(parquet.BooleanType)
+	case reflect.Float32:
+		return parquet.Leaf(parquet.FloatType)
+	case reflect.Float64:
+		return parquet.Leaf(parquet.DoubleType)
+	default:
+		return parquet.Int(64)
+	}
+}
+
+// shred appends the column values of v to columns. rep and def are the
+// repetition and definition levels of the parent, depth is its repetition depth.
+func (f *pqField) shred(columns [][]parquet.Value, v reflect.Value, rep, def, depth int) {
+	v = indirect(v)
+	if !v.IsValid() || ((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil()) {
+		f.null(columns, rep, def)
+		return
+	}
+	def++ // the optional field itself is defined
+	switch f.kind {
+	case pqLeaf:
+		columns[f.column] = append(columns[f.column], leafValue(v).Level(rep, def, f.column))
+	case pqGroup:
+		for _, c := range f.children {
+			child, err := v.FieldByIndexErr(c.index)
+			if err != nil {
+				child = reflect.Value{}
+			}
+			c.shred(columns, child, rep, def, depth)
+		}
+	case pqList:
+		if v.Len() == 0 {
+			f.elem.null(columns, rep, def)
+			return
+		}
+		for i := range v.Len() {
+			r := rep
+			if i > 0 {
+				r = depth + 1
+			}
+			f.elem.shred(columns, v.Index(i), r, def+1, depth+1)
+		}
+	case pqMap:
+		if v.Len() == 0 {
+			f.null(columns, rep, def)
+			return
+		}
+		keys := v.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(a.String(), b.String()) })
+		for i, k := range keys {
+			r := rep
+			if i > 0 {
+				r = depth + 1
+			}
+			columns[f.key] = append(columns[f.key], parquet.ByteArrayValue([]byte(k.String())).Level(r, def+1, f.key))
+			f.elem.shred(columns, v.MapIndex(k), r, def+1, depth+1)
+		}
+	}
+}
+
+// null writes a null at the given levels for every leaf below f.
+func (f *pqField) null(columns [][]parquet.Value, rep, def int) {
+	for _, c := range f.columns {
+		columns[c] = append(columns[c], parquet.Value{}.Level(rep, def, c))
+	}
+}
+
+func leafValue(v reflect.Value) parquet.Value {
+	switch v.Kind() {
+	case reflect.String:
+		return parquet.ByteArrayValue([]byte(v.String()))
+	case reflect.Bool:
+		return parquet.BooleanValue(v.Bool())
+	case reflect.Float32:
+		return parquet.FloatValue(float32(v.Float()))
+	case reflect.Float64:
+		return parquet.DoubleValue(v.Float())
+	default:
+		return parquet.Int64Value(v.Int())
+	}
+}
+
+// ParquetWriter streams entities of type T into a parquet file, writing a row
+// group every RowGroupSize rows so that memory use stays bounded.
+type ParquetWriter[T any] struct {
+	writer       *parquet.Writer
+	root         *pqField
+	columns      int
+	rowGroupSize int
+	buffered     int
+}
+
+// NewParquetWriter creates a ParquetWriter for T with a schema derived by ParquetSchema.
+func NewParquetWriter[T any](w io.Writer, opts ParquetOptions) (*ParquetWriter[T], error) {
+	root, err := parquetRoot(reflect.TypeFor[T]())
+	if err != nil {
+		return nil, err
+	}
+	codec, err := compressionCodec(opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	size := opts.RowGroupSize
+	if size <= 0 {
+		size = DefaultRowGroupSize
+	}
+	schema := parquet.NewSchema(schemaName(reflect.TypeFor[T]()), root.node().(parquet.Group))
+	return &ParquetWriter[T]{
+		writer:       parquet.NewWriter(w, schema, parquet.Compression(codec)),
+		root:         root,
+		columns:      len(root.columns),
+		rowGroupSize: size,
+	}, nil
+}
+
+// Write appends records to the current row group, flushing it once full.
+func (w *ParquetWriter[T]) Write(records ...*T) error {
+	for _, record := range records {
+		if record == nil {
+			continue
+		}
+		columns := make([][]parquet.Value, w.columns)
+		rv := reflect.ValueOf(record).Elem()
+		for _, c := range w.root.children {
+			c.shred(columns, rv.FieldByIndex(c.index), 0, 0, 0)
+		}
+		if _, err := w.writer.WriteRows([]parquet.Row{slices.Concat(columns...)}); err != nil {
+			return err
+		}
+		w.buffered++
+		if w.buffered >= w.rowGroupSize {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Flush writes the buffered rows as a row group.
+func (w *ParquetWriter[T]) Flush() error {
+	if w.buffered == 0 {
+		return nil
+	}
+	w.buffered = 0
+	return w.writer.Flush()
+}
+
+// Close flushes any buffered rows and writes the parquet footer. It does not
+// close the underlying writer.
+func (w *ParquetWriter[T]) Close() error {
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return w.writer.Close()
+}
+
+// QueryParquet streams every result of q to w as parquet using cursor
+// pagination and returns the number of records written. q itself is not
+// changed.
+func QueryParquet[T any](ctx context.Context, q *core.QueryBuilder[T], w io.Writer, opts ParquetOptions) (int, error) {
+	q = q.Clone()
+	pw, err := NewParquetWriter[T](w, opts)
+	if err != nil {
+		return 0, err
+	}
+	perPage := opts.PerPage
+	if perPage <= 0 {
+		perPage = 200
+	}
+	q.PerPage(perPage)
+
+	written := 0
+	cursor := "*"
+	for cursor != "" {
+		results, next, err := q.CursorWithContext(ctx, cursor)
+		if err != nil {
+			return written, err
+		}
+		if opts.Limit > 0 && written+len(results) > opts.Limit {
+			results = results[:opts.Limit-written]
+			next = ""
+		}
+		if err := pw.Write(results...); err != nil {
+			return written, err
+		}
+		written += len(results)
+		if len(results) == 0 {
+			break
+		}
+		cursor = next
+	}
+	return written, pw.Close()
+}
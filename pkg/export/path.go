@@ -0,0 +1,230 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// segment is a single step of a dotted column path.
+type segment struct {
+	name  string
+	index int // -1 for every element ("[*]"), -2 when no index was given
+}
+
+const (
+	indexAll  = -1
+	indexNone = -2
+)
+
+// Path is a compiled dotted path such as "primary_location.source.display_name"
+// or "authorships[*].author.display_name".
+type Path struct {
+	raw      string
+	segments []segment
+}
+
+// ParsePath compiles a dotted path. Slices may be indexed with "[n]" or expanded
+// with "[*]"; a slice without an index is expanded as well.
+func ParsePath(raw string) (*Path, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	p := &Path{raw: raw}
+	for part := range strings.SplitSeq(raw, ".") {
+		seg := segment{name: part, index: indexNone}
+		if open := strings.IndexByte(part, '['); open >= 0 {
+			if !strings.HasSuffix(part, "]") {
+				return nil, fmt.Errorf("invalid path %q: unterminated index", raw)
+			}
+			seg.name = part[:open]
+			idx := part[open+1 : len(part)-1]
+			if idx == "*" {
+				seg.index = indexAll
+			} else {
+				n, err := strconv.Atoi(idx)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("invalid path %q: bad index %q", raw, idx)
+				}
+				seg.index = n
+			}
+		}
+		if seg.name == "" {
+			return nil, fmt.Errorf("invalid path %q: empty segment", raw)
+		}
+		p.segments = append(p.segments, seg)
+	}
+	return p, nil
+}
+
+// String returns the path as it was written.
+func (p *Path) String() string {
+	return p.raw
+}
+
+// Root returns the top-level field of the path, suitable for Select.
+func (p *Path) Root() string {
+	return p.segments[0].name
+}
+
+// expands reports whether the path may resolve to more than one value.
+func (p *Path) expands() bool {
+	for _, seg := range p.segments {
+		if seg.index == indexAll {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve walks v along the path and returns every leaf value reached.
+// Missing or nil values yield no results rather than an error.
+func (p *Path) Resolve(v any) ([]reflect.Value, error) {
+	current := []reflect.Value{reflect.ValueOf(v)}
+	for _, seg := range p.segments {
+		var next []reflect.Value
+		for _, rv := range current {
+			child, ok, err := lookup(rv, seg.name)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: %w", p.raw, err)
+			}
+			if !ok {
+				continue
+			}
+			next = append(next, expand(child, seg.index)...)
+		}
+		current = next
+	}
+
+	// A slice at the leaf is flattened so that "keywords" behaves like "keywords[*]".
+	var leaves []reflect.Value
+	for _, rv := range current {
+		leaves = append(leaves, expand(rv, indexNone)...)
+	}
+	return leaves, nil
+}
+
+// expand applies an index to rv if it is a slice; other values pass through.
+func expand(rv reflect.Value, index int) []reflect.Value {
+	rv = indirect(rv)
+	if !rv.IsValid() {
+		return nil
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []reflect.Value{rv}
+	}
+	// Byte slices are values, not lists.
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return []reflect.Value{rv}
+	}
+	if index >= 0 {
+		if index >= rv.Len() {
+			return nil
+		}
+		return expand(rv.Index(index), indexNone)
+	}
+	out := make([]reflect.Value, 0, rv.Len())
+	for i := range rv.Len() {
+		out = append(out, expand(rv.Index(i), indexNone)...)
+	}
+	return out
+}
+
+// lookup returns the child named name of a struct (by JSON tag) or map (by key).
+func lookup(rv reflect.Value, name string) (reflect.Value, bool, error) {
+	rv = indirect(rv)
+	if !rv.IsValid() {
+		return reflect.Value{}, false, nil
+	}
+	switch rv.Kind() {
+	case reflect.Struct:
+		index, ok := fieldsOf(rv.Type())[name]
+		if !ok {
+			return reflect.Value{}, false, fmt.Errorf("unknown field %q on %s", name, rv.Type())
+		}
+		field, err := rv.FieldByIndexErr(index)
+		if err != nil {
+			return reflect.Value{}, false, nil
+		}
+		return field, true, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false, fmt.Errorf("unsupported map key type %s", rv.Type().Key())
+		}
+		child := rv.MapIndex(reflect.ValueOf(name).Convert(rv.Type().Key()))
+		return child, child.IsValid(), nil
+	default:
+		return reflect.Value{}, false, fmt.Errorf("cannot select %q from %s", name, rv.Type())
+	}
+}
+
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.IsValid() && (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface) {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+var fieldCache sync.Map // map[reflect.Type]map[string][]int
+
+// fieldsOf maps the JSON names of a struct type, including promoted fields of
+// embedded structs, to their field index.
+func fieldsOf(t reflect.Type) map[string][]int {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+	fields := make(map[string][]int)
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		// Shallower fields win, matching encoding/json.
+		if existing, ok := fields[name]; ok && len(existing) <= len(f.Index) {
+			continue
+		}
+		fields[name] = f.Index
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// formatValue renders a leaf value as a flat string.
+func formatValue(rv reflect.Value) (string, error) {
+	rv = indirect(rv)
+	if !rv.IsValid() {
+		return "", nil
+	}
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 64), nil
+	default:
+		b, err := json.Marshal(rv.Interface())
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
@@ -0,0 +1,254 @@
+// Package mcp serves OpenAlex queries to language-model agents over the Model
+// Context Protocol. Searching works, fetching entities by any identifier,
+// group-by counts, autocomplete and citation neighbors are exposed as tools
+// with JSON schemas, and every call goes through a core.Client so agents get
+// the same retries, polite pool and API key as Go callers.
+//
+// The server speaks JSON-RPC 2.0 over stdio (ServeStdio) or over HTTP POST
+// (Server implements http.Handler), answering each request with a single
+// JSON response.
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/Sunhill666/goalex/internal/ratelimit"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// ProtocolVersion is the latest protocol revision implemented by the server.
+const ProtocolVersion = "2025-06-18"
+
+var supportedVersions = []string{ProtocolVersion, "2025-03-26", "2024-11-05"}
+
+// Defaults applied by NewServer.
+const (
+	DefaultMaxResults = 25
+	DefaultMaxBytes   = 32000
+	DefaultRateLimit  = 5
+	DefaultBurst      = 10
+)
+
+// Options configures a Server.
+type Options struct {
+	// Name and Version identify the server to clients. Name defaults to "goalex".
+	Name    string
+	Version string
+	// MaxResults caps the number of results a tool returns. Defaults to DefaultMaxResults.
+	MaxResults int
+	// MaxBytes caps the size of the JSON a tool returns; larger results are
+	// truncated. Defaults to DefaultMaxBytes.
+	MaxBytes int
+	// RateLimit is the sustained number of tool calls allowed per second.
+	// Defaults to DefaultRateLimit; a negative value disables rate limiting.
+	RateLimit float64
+	// Burst is the number of tool calls allowed at once. Defaults to DefaultBurst.
+	Burst int
+}
+
+// Server exposes OpenAlex operations as MCP tools.
+type Server struct {
+	client  *core.Client
+	opts    Options
+	limiter *ratelimit.Limiter
+	tools   []*tool
+
+	mu       sync.Mutex
+	inflight map[string]context.CancelFunc
+}
+
+// NewServer returns a server that answers tool calls with c.
+func NewServer(c *core.Client, opts Options) *Server {
+	if opts.Name == "" {
+		opts.Name = "goalex"
+	}
+	if opts.Version == "" {
+		opts.Version = "dev"
+	}
+	if opts.MaxResults <= 0 {
+		opts.MaxResults = DefaultMaxResults
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.RateLimit == 0 {
+		opts.RateLimit = DefaultRateLimit
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = DefaultBurst
+	}
+	s := &Server{
+		client:   c,
+		opts:     opts,
+		limiter:  ratelimit.New(opts.RateLimit, opts.Burst),
+		inflight: make(map[string]context.CancelFunc),
+	}
+	s.tools = s.newTools()
+	return s
+}
+
+// JSON-RPC error codes.
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Handle processes a single JSON-RPC message and returns the encoded response,
+// or nil for notifications.
+func (s *Server) Handle(ctx context.Context, msg []byte) []byte {
+	var req request
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return encode(nil, nil, &rpcError{Code: codeParseError, Message: err.Error()})
+	}
+	if len(req.ID) == 0 {
+		s.notify(&req)
+		return nil
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return encode(req.ID, nil, &rpcError{Code: codeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"})
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	key := string(req.ID)
+	s.mu.Lock()
+	s.inflight[key] = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.inflight, key)
+		s.mu.Unlock()
+		cancel()
+	}()
+
+	result, rerr := s.dispatch(ctx, req.Method, req.Params)
+	return encode(req.ID, result, rerr)
+}
+
+func encode(id json.RawMessage, result any, rerr *rpcError) []byte {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	b, err := json.Marshal(&response{JSONRPC: "2.0", ID: id, Result: result, Error: rerr})
+	if err != nil {
+		b, _ = json.Marshal(&response{JSONRPC: "2.0", ID: id, Error: &rpcError{Code: codeInternalError, Message: err.Error()}})
+	}
+	return b
+}
+
+// notify handles notifications. Only cancellation has an effect.
+func (s *Server) notify(req *request) {
+	if req.Method != "notifications/cancelled" {
+		return
+	}
+	var params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	}
+	if json.Unmarshal(req.Params, &params) != nil {
+		return
+	}
+	s.mu.Lock()
+	cancel := s.inflight[string(params.RequestID)]
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+func (s *Server) dispatch(ctx context.Context, method string, params json.RawMessage) (any, *rpcError) {
+	switch method {
+	case "initialize":
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(params, &p)
+		version := ProtocolVersion
+		for _, v := range supportedVersions {
+			if v == p.ProtocolVersion {
+				version = v
+			}
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{"listChanged": false}},
+			"serverInfo":      map[string]any{"name": s.opts.Name, "version": s.opts.Version},
+			"instructions":    "Query the OpenAlex catalog of scholarly works, authors, sources, institutions and topics. Results are capped and may be truncated; narrow queries with filters and select.",
+		}, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "tools/list":
+		return map[string]any{"tools": s.tools}, nil
+	case "tools/call":
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		for _, t := range s.tools {
+			if t.Name == p.Name {
+				return s.call(ctx, t, p.Arguments), nil
+			}
+		}
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + p.Name}
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + method}
+	}
+}
+
+// toolResult is the result of tools/call. Failures are reported with IsError
+// rather than as protocol errors so that the agent can correct its arguments.
+type toolResult struct {
+	Content           []content `json:"content"`
+	StructuredContent any       `json:"structuredContent,omitempty"`
+	IsError           bool      `json:"isError,omitempty"`
+}
+
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func (s *Server) call(ctx context.Context, t *tool, args json.RawMessage) *toolResult {
+	if err := s.limiter.Wait(ctx); err != nil {
+		return errorResult(err)
+	}
+	result, err := t.call(ctx, args)
+	if err != nil {
+		return errorResult(err)
+	}
+	truncate(result, s.opts.MaxBytes)
+	b, err := json.Marshal(result)
+	if err != nil {
+		return errorResult(err)
+	}
+	return &toolResult{Content: []content{{Type: "text", Text: string(b)}}, StructuredContent: result}
+}
+
+func errorResult(err error) *toolResult {
+	return &toolResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}
+}
@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// schema is the subset of JSON Schema used to describe tool arguments.
+type schema struct {
+	Type                 string             `json:"type"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+}
+
+// schemaFor derives a schema from a Go type. Struct fields are named by their
+// json tags and described by the description, enum, default, minimum, maximum
+// and required tags. Embedded structs are flattened as encoding/json does.
+func schemaFor(t reflect.Type) *schema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &schema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Struct:
+		s := &schema{Type: "object", Properties: make(map[string]*schema), AdditionalProperties: false}
+		for _, f := range reflect.VisibleFields(t) {
+			if f.Anonymous || !f.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			p := schemaFor(f.Type)
+			p.Description = f.Tag.Get("description")
+			if enum := f.Tag.Get("enum"); enum != "" {
+				p.Enum = strings.Split(enum, ",")
+			}
+			if def, ok := f.Tag.Lookup("default"); ok {
+				p.Default = def
+				if n, err := strconv.Atoi(def); err == nil && p.Type == "integer" {
+					p.Default = n
+				}
+			}
+			p.Minimum = intTag(f.Tag, "minimum")
+			p.Maximum = intTag(f.Tag, "maximum")
+			s.Properties[name] = p
+			if f.Tag.Get("required") == "true" {
+				s.Required = append(s.Required, name)
+			}
+		}
+		return s
+	default:
+		return &schema{Type: "object"}
+	}
+}
+
+func intTag(tag reflect.StructTag, key string) *int {
+	n, err := strconv.Atoi(tag.Get(key))
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+// decodeArgs decodes tool arguments into args, rejecting unknown fields and
+// missing required ones so that the agent learns what it got wrong.
+func decodeArgs(raw json.RawMessage, s *schema, args any) error {
+	if len(bytes.TrimSpace(raw)) == 0 || string(raw) == "null" {
+		raw = json.RawMessage("{}")
+	}
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &present); err != nil {
+		return fmt.Errorf("arguments must be a JSON object: %w", err)
+	}
+	for _, name := range s.Required {
+		if _, ok := present[name]; !ok {
+			return fmt.Errorf("missing required argument %q", name)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(args); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	for name, p := range s.Properties {
+		if len(p.Enum) == 0 {
+			continue
+		}
+		var v string
+		if raw, ok := present[name]; ok && json.Unmarshal(raw, &v) == nil && v != "" && !slices.Contains(p.Enum, v) {
+			return fmt.Errorf("invalid %s %q, expected one of %s", name, v, strings.Join(p.Enum, ", "))
+		}
+	}
+	return nil
+}
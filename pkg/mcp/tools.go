@@ -0,0 +1,393 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// tool is an operation advertised by tools/list.
+type tool struct {
+	Name        string  `json:"name"`
+	Title       string  `json:"title,omitempty"`
+	Description string  `json:"description"`
+	InputSchema *schema `json:"inputSchema"`
+
+	call func(ctx context.Context, args json.RawMessage) (map[string]any, error)
+}
+
+// newTool builds a tool whose input schema is derived from the argument type A.
+func newTool[A any](name, title, description string, fn func(context.Context, *A) (map[string]any, error)) *tool {
+	s := schemaFor(reflect.TypeFor[A]())
+	return &tool{
+		Name:        name,
+		Title:       title,
+		Description: description,
+		InputSchema: s,
+		call: func(ctx context.Context, raw json.RawMessage) (map[string]any, error) {
+			var args A
+			if err := decodeArgs(raw, s, &args); err != nil {
+				return nil, err
+			}
+			return fn(ctx, &args)
+		},
+	}
+}
+
+// endpoints maps the entity names accepted by the tools to API endpoints.
+var endpoints = map[string]string{
+	"works":        core.EndpointWorks,
+	"authors":      core.EndpointAuthors,
+	"sources":      core.EndpointSources,
+	"institutions": core.EndpointInstitutions,
+	"topics":       core.EndpointTopics,
+	"keywords":     core.EndpointKeywords,
+	"publishers":   core.EndpointPublishers,
+	"funders":      core.EndpointFunders,
+	"concepts":     core.EndpointConcepts,
+}
+
+// neighborFields are selected for the works returned by citation_neighbors.
+var neighborFields = []string{"id", "doi", "display_name", "publication_year", "cited_by_count"}
+
+// queryArgs mirrors the QueryBuilder options shared by the listing tools.
+type queryArgs struct {
+	Filter  map[string]string `json:"filter,omitempty" description:"Filters keyed by field, e.g. {\"publication_year\": \"2020\", \"authorships.institutions.id\": \"I27837315\"}. Values accept | for OR, ! for NOT and < or > for ranges."`
+	Sort    []string          `json:"sort,omitempty" description:"Sort fields, with :desc for descending order, e.g. [\"cited_by_count:desc\"]."`
+	Select  []string          `json:"select,omitempty" description:"Top-level fields to return, e.g. [\"id\", \"display_name\", \"publication_year\"]. Selecting fields keeps results small."`
+	Page    int               `json:"page,omitempty" description:"Page number, starting at 1." minimum:"1"`
+	PerPage int               `json:"per_page,omitempty" description:"Results per page; capped by the server." minimum:"1" maximum:"200"`
+	Sample  int               `json:"sample,omitempty" description:"Return a random sample of this many results." minimum:"1"`
+	Seed    int               `json:"seed,omitempty" description:"Seed for a reproducible sample."`
+}
+
+// apply copies the arguments onto q, capping the page size at maxResults.
+func (a *queryArgs) apply(q *core.QueryBuilder[json.RawMessage], maxResults int) {
+	for field, value := range a.Filter {
+		q.Filter(field, value)
+	}
+	for _, s := range a.Sort {
+		field, dir, _ := strings.Cut(s, ":")
+		q.Sort(field, dir == "desc")
+	}
+	q.Select(a.Select...)
+	q.Sample(a.Sample)
+	if a.Seed != 0 {
+		q.Seed(a.Seed)
+	}
+	if a.Page > 0 {
+		q.Page(a.Page)
+	}
+	perPage := a.PerPage
+	if perPage <= 0 || perPage > maxResults {
+		perPage = maxResults
+	}
+	q.PerPage(perPage)
+}
+
+type searchWorksArgs struct {
+	Query string `json:"query,omitempty" description:"Full-text search over titles, abstracts and full text."`
+	queryArgs
+}
+
+type getEntityArgs struct {
+	ID     string `json:"id" required:"true" description:"Any identifier: an OpenAlex ID or URL (W..., A..., S..., I..., T...), DOI, ORCID, ROR, ISSN, PMID or PMCID."`
+	Entity string `json:"entity,omitempty" enum:"works,authors,sources,institutions,topics,keywords,publishers,funders,concepts" description:"Entity type; inferred from the identifier when omitted."`
+}
+
+type groupByArgs struct {
+	Entity         string            `json:"entity,omitempty" enum:"works,authors,sources,institutions,topics,keywords,publishers,funders,concepts" default:"works" description:"Entity type to count."`
+	Field          string            `json:"field" required:"true" description:"Field to group by, e.g. publication_year, open_access.oa_status or authorships.institutions.id."`
+	Query          string            `json:"query,omitempty" description:"Full-text search applied before grouping."`
+	Filter         map[string]string `json:"filter,omitempty" description:"Filters keyed by field, as for search_works."`
+	IncludeUnknown bool              `json:"include_unknown,omitempty" description:"Include the group of entities without a value."`
+}
+
+type autocompleteArgs struct {
+	Query  string            `json:"query" required:"true" description:"Prefix typed so far, e.g. \"harv\"."`
+	Entity string            `json:"entity,omitempty" enum:"works,authors,sources,institutions,topics,keywords,publishers,funders,concepts" default:"works" description:"Entity type to complete."`
+	Filter map[string]string `json:"filter,omitempty" description:"Filters keyed by field, as for search_works."`
+}
+
+type citationArgs struct {
+	ID        string `json:"id" required:"true" description:"Work identifier: an OpenAlex work ID, DOI, PMID or PMCID."`
+	Direction string `json:"direction,omitempty" enum:"references,cited_by,both" default:"both" description:"Return the works it cites, the works citing it, or both."`
+	Limit     int    `json:"limit,omitempty" description:"Maximum number of works in each direction; capped by the server." minimum:"1"`
+}
+
+func (s *Server) newTools() []*tool {
+	return []*tool{
+		newTool("search_works", "Search works",
+			"Search and filter scholarly works (articles, books, datasets). Returns the total count and one page of results.",
+			s.searchWorks),
+		newTool("get_entity", "Get entity",
+			"Fetch a single work, author, source, institution or other entity by any of its identifiers. Reports the canonical ID when the entity was merged.",
+			s.getEntity),
+		newTool("group_by", "Group by",
+			"Count entities grouped by a field, e.g. works per publication year or per open-access status.",
+			s.groupBy),
+		newTool("autocomplete", "Autocomplete",
+			"Suggest entities whose names start with a prefix. Useful to turn a name into an OpenAlex ID for filters.",
+			s.autocomplete),
+		newTool("citation_neighbors", "Citation neighbors",
+			"List the works a work cites and the most cited works citing it.",
+			s.citationNeighbors),
+	}
+}
+
+func (s *Server) searchWorks(ctx context.Context, args *searchWorksArgs) (map[string]any, error) {
+	q := core.Query[json.RawMessage](s.client, core.EndpointWorks)
+	if args.Query != "" {
+		q.Search(args.Query)
+	}
+	args.apply(q, s.opts.MaxResults)
+	resp, err := q.ListWithMetaContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listResult(total(resp.Meta), resp.Results), nil
+}
+
+func (s *Server) getEntity(ctx context.Context, args *getEntityArgs) (map[string]any, error) {
+	endpoint, key, err := locate(args.ID, args.Entity)
+	if err != nil {
+		return nil, err
+	}
+	raw, res, err := core.GetEntityWithResolution[json.RawMessage](ctx, s.client, endpoint, key)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{
+		"canonical_id": res.CanonicalID,
+		"entity":       decode(*raw),
+	}
+	if res.Redirected {
+		result["redirected_from"] = args.ID
+	}
+	return result, nil
+}
+
+func (s *Server) groupBy(ctx context.Context, args *groupByArgs) (map[string]any, error) {
+	q := core.Query[json.RawMessage](s.client, endpoints[entityOrWorks(args.Entity)])
+	if args.Query != "" {
+		q.Search(args.Query)
+	}
+	for field, value := range args.Filter {
+		q.Filter(field, value)
+	}
+	resp, err := q.GroupBy(args.Field, args.IncludeUnknown).ListWithMetaContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// The groups are listed under results, so that truncate can trim them.
+	groups := resp.GroupBy[:min(len(resp.GroupBy), s.opts.MaxResults)]
+	result := listResult(total(resp.Meta), groups)
+	result["groups_count"] = len(resp.GroupBy)
+	if len(groups) < len(resp.GroupBy) {
+		result["truncated"] = true
+	}
+	return result, nil
+}
+
+func (s *Server) autocomplete(ctx context.Context, args *autocompleteArgs) (map[string]any, error) {
+	q := core.Query[json.RawMessage](s.client, endpoints[entityOrWorks(args.Entity)])
+	for field, value := range args.Filter {
+		q.Filter(field, value)
+	}
+	resp, err := q.AutoComplete(args.Query).ListWithMetaContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := resp.Results[:min(len(resp.Results), s.opts.MaxResults)]
+	return listResult(total(resp.Meta), results), nil
+}
+
+func (s *Server) citationNeighbors(ctx context.Context, args *citationArgs) (map[string]any, error) {
+	endpoint, key, err := locate(args.ID, "works")
+	if err != nil {
+		return nil, err
+	}
+	if endpoint != core.EndpointWorks {
+		return nil, fmt.Errorf("%s is not a work identifier", args.ID)
+	}
+	limit := args.Limit
+	if limit <= 0 || limit > s.opts.MaxResults {
+		limit = s.opts.MaxResults
+	}
+	work, _, err := core.GetEntityWithResolution[struct {
+		ID              string   `json:"id"`
+		DisplayName     string   `json:"display_name"`
+		PublicationYear int      `json:"publication_year"`
+		CitedByCount    int      `json:"cited_by_count"`
+		ReferencedWorks []string `json:"referenced_works"`
+	}](ctx, s.client, endpoint, key)
+	if err != nil {
+		return nil, err
+	}
+	result := map[string]any{"work": map[string]any{
+		"id":               work.ID,
+		"display_name":     work.DisplayName,
+		"publication_year": work.PublicationYear,
+		"cited_by_count":   work.CitedByCount,
+	}}
+
+	direction := args.Direction
+	if direction == "" {
+		direction = "both"
+	}
+	if direction != "cited_by" {
+		refs := work.ReferencedWorks[:min(limit, len(work.ReferencedWorks))]
+		var results []*json.RawMessage
+		if len(refs) > 0 {
+			ids := make([]string, len(refs))
+			for i, id := range refs {
+				ids[i] = core.ShortID(id)
+			}
+			resp, err := core.Query[json.RawMessage](s.client, core.EndpointWorks).
+				Filter(core.FilterOpenAlexID, strings.Join(ids, "|")).
+				Select(neighborFields...).
+				PerPage(len(ids)).
+				ListWithMetaContext(ctx)
+			if err != nil {
+				return nil, err
+			}
+			results = resp.Results
+		}
+		result["references"] = listResult(len(work.ReferencedWorks), results)
+	}
+	if direction != "references" {
+		resp, err := core.Query[json.RawMessage](s.client, core.EndpointWorks).
+			Filter("cites", core.ShortID(work.ID)).
+			Sort("cited_by_count", true).
+			Select(neighborFields...).
+			PerPage(limit).
+			ListWithMetaContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result["cited_by"] = listResult(total(resp.Meta), resp.Results)
+	}
+	return result, nil
+}
+
+func entityOrWorks(entity string) string {
+	if entity == "" {
+		return "works"
+	}
+	return entity
+}
+
+func total(meta *model.PaginatedResponseMeta) int {
+	if meta == nil {
+		return 0
+	}
+	return meta.Count
+}
+
+// listResult pairs the total count of a query with its results, decoded into
+// generic values.
+func listResult[T any](count int, results []*T) map[string]any {
+	items := make([]any, 0, len(results))
+	for _, r := range results {
+		if b, err := json.Marshal(r); err == nil && r != nil {
+			items = append(items, decode(b))
+		}
+	}
+	return map[string]any{"count": count, "results": items}
+}
+
+// decode turns raw JSON into generic values that truncate can trim, keeping
+// numbers exact.
+func decode(raw json.RawMessage) any {
+	dec := json.NewDecoder(strings.NewReader(string(raw)))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return raw
+	}
+	return v
+}
+
+var (
+	orcidPattern = regexp.MustCompile(`^\d{4}-\d{4}-\d{4}-\d{3}[\dX]$`)
+	issnPattern  = regexp.MustCompile(`^\d{4}-\d{3}[\dXx]$`)
+	pmcidPattern = regexp.MustCompile(`^(?i)PMC\d+$`)
+)
+
+// externalIDs maps URL and namespace prefixes of external identifiers to the
+// entity they identify and the namespace OpenAlex expects in the path.
+var externalIDs = []struct {
+	prefixes  []string
+	entity    string
+	namespace string
+}{
+	{[]string{"https://doi.org/", "http://doi.org/", "doi.org/", "doi:"}, "works", "doi"},
+	{[]string{"https://pubmed.ncbi.nlm.nih.gov/", "pmid:"}, "works", "pmid"},
+	{[]string{"pmcid:"}, "works", "pmcid"},
+	{[]string{"https://orcid.org/", "http://orcid.org/", "orcid.org/", "orcid:"}, "authors", "orcid"},
+	{[]string{"https://ror.org/", "http://ror.org/", "ror.org/", "ror:"}, "institutions", "ror"},
+	{[]string{"issn:", "issn_l:"}, "sources", "issn"},
+	{[]string{"https://www.wikidata.org/wiki/", "wikidata:"}, "", "wikidata"},
+	{[]string{"mag:"}, "", "mag"},
+}
+
+// entityLetters maps the first letter of an OpenAlex ID to its entity.
+var entityLetters = map[byte]string{
+	'W': "works", 'A': "authors", 'S': "sources", 'I': "institutions", 'T': "topics",
+	'K': "keywords", 'P': "publishers", 'F': "funders", 'C': "concepts",
+}
+
+// locate maps an identifier to the endpoint serving it and the path segment
+// OpenAlex accepts for it. entity overrides the inferred entity type.
+func locate(id, entity string) (endpoint, key string, err error) {
+	id = strings.TrimSpace(id)
+	if core.IsOpenAlexID(id) {
+		key = core.ShortID(id)
+		if entity == "" {
+			entity = entityLetters[key[0]]
+		}
+		return endpoints[entity], key, nil
+	}
+
+	inferred, namespace, value := "", "", id
+	lower := strings.ToLower(id)
+	for _, e := range externalIDs {
+		for _, p := range e.prefixes {
+			if strings.HasPrefix(lower, p) {
+				inferred, namespace, value = e.entity, e.namespace, id[len(p):]
+				break
+			}
+		}
+		if namespace != "" {
+			break
+		}
+	}
+	if namespace == "" {
+		switch {
+		case strings.HasPrefix(id, "10."):
+			inferred, namespace = "works", "doi"
+		case orcidPattern.MatchString(id):
+			inferred, namespace = "authors", "orcid"
+		case issnPattern.MatchString(id):
+			inferred, namespace = "sources", "issn"
+		case pmcidPattern.MatchString(id):
+			inferred, namespace = "works", "pmcid"
+		}
+	}
+	if namespace == "" {
+		return "", "", fmt.Errorf("unrecognized identifier %q", id)
+	}
+	if entity == "" {
+		entity = inferred
+	}
+	if entity == "" {
+		return "", "", fmt.Errorf("cannot infer the entity type of %q, pass entity", id)
+	}
+	return endpoints[entity], namespace + ":" + value, nil
+}
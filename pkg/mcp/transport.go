@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// maxMessageSize bounds the size of a single incoming message.
+const maxMessageSize = 1 << 20
+
+// ServeStdio reads newline-delimited JSON-RPC messages from r and writes the
+// responses to w until r reaches EOF or ctx is done. Requests are handled
+// concurrently, so a slow tool call does not block pings or cancellations.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		br := bufio.NewReaderSize(r, 64*1024)
+		for {
+			line, err := br.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr <- err
+				}
+				return
+			}
+		}
+	}()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case line, ok := <-lines:
+			if !ok {
+				select {
+				case err := <-readErr:
+					return err
+				default:
+					return nil
+				}
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				out := s.Handle(ctx, line)
+				if out == nil {
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				_, _ = w.Write(append(out, '\n'))
+			}()
+		}
+	}
+}
+
+// ServeHTTP implements the request side of the streamable HTTP transport:
+// each POSTed message is answered with a JSON response, and notifications
+// with 202 Accepted. The server does not open server-initiated streams.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body) > maxMessageSize {
+		http.Error(w, "message too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	out := s.Handle(r.Context(), body)
+	if out == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
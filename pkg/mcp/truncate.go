@@ -0,0 +1,151 @@
+package mcp
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"slices"
+)
+
+// protectedFields identify a record or group and are never dropped by truncate.
+var protectedFields = map[string]bool{
+	"id":               true,
+	"doi":              true,
+	"display_name":     true,
+	"publication_year": true,
+	"cited_by_count":   true,
+	"works_count":      true,
+	"key":              true,
+	"count":            true,
+}
+
+// truncate shrinks a tool result until it encodes to at most limit bytes.
+// The largest fields of the returned records are dropped first and listed
+// under omitted_fields, so that agents keep as many results as possible;
+// trailing results of the longest lists go next, and the identifying fields
+// last. Sizes are computed once per pass from the encoded values, and every
+// pass ends by checking the encoded result, markers included, against limit.
+// Only a limit smaller than the emptied result cannot be met.
+func truncate(result map[string]any, limit int) {
+	if size(result) <= limit {
+		return
+	}
+	result["truncated"] = true
+
+	var omitted []string
+	for total := size(result); total > limit; {
+		// Records are collected again as every pass may drop results.
+		var records []map[string]any
+		var lists []map[string]any
+		collect(result, &records, &lists)
+		excess := total - limit
+		freed := dropFields(records, &omitted, excess, false)
+		if freed < excess {
+			freed += dropItems(lists, excess-freed)
+		}
+		if freed < excess {
+			dropFields(records, &omitted, excess-freed, true)
+		}
+		if len(omitted) > 0 {
+			slices.Sort(omitted)
+			result["omitted_fields"] = omitted
+		}
+		next := size(result)
+		if next >= total {
+			// Nothing is left to drop.
+			break
+		}
+		total = next
+	}
+}
+
+// dropFields drops the largest fields of records, across all of them, until
+// about need bytes are freed, and returns the bytes freed. Only protected or
+// only unprotected fields are dropped.
+func dropFields(records []map[string]any, omitted *[]string, need int, protected bool) int {
+	sizes := make(map[string]int)
+	for _, r := range records {
+		for name, v := range r {
+			if protectedFields[name] == protected {
+				// The member is encoded as "name":value followed by a comma.
+				sizes[name] += len(name) + 4 + size(v)
+			}
+		}
+	}
+	names := slices.Collect(maps.Keys(sizes))
+	slices.SortFunc(names, func(a, b string) int {
+		if c := cmp.Compare(sizes[b], sizes[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	freed := 0
+	for _, name := range names {
+		if freed >= need {
+			break
+		}
+		for _, r := range records {
+			delete(r, name)
+		}
+		*omitted = append(*omitted, name)
+		freed += sizes[name]
+	}
+	return freed
+}
+
+// dropItems drops trailing results of the longest lists until about need
+// bytes are freed, and returns the bytes freed. Lists keep one result for as
+// long as another list has more.
+func dropItems(lists []map[string]any, need int) int {
+	sizes := make([][]int, len(lists))
+	for i, l := range lists {
+		for _, item := range l["results"].([]any) {
+			sizes[i] = append(sizes[i], size(item)+1)
+		}
+	}
+	freed := 0
+	for freed < need {
+		longest := -1
+		for i, l := range lists {
+			if n := len(l["results"].([]any)); n > 0 && (longest < 0 || n > len(lists[longest]["results"].([]any))) {
+				longest = i
+			}
+		}
+		if longest < 0 {
+			break
+		}
+		items := lists[longest]["results"].([]any)
+		lists[longest]["results"] = items[:len(items)-1]
+		freed += sizes[longest][len(items)-1]
+	}
+	return freed
+}
+
+// collect finds the records of a result: the elements of results lists and
+// the value of entity. lists receives the objects holding a results list.
+func collect(m map[string]any, records *[]map[string]any, lists *[]map[string]any) {
+	if items, ok := m["results"].([]any); ok {
+		*lists = append(*lists, m)
+		for _, item := range items {
+			if r, ok := item.(map[string]any); ok {
+				*records = append(*records, r)
+			}
+		}
+	}
+	if r, ok := m["entity"].(map[string]any); ok {
+		*records = append(*records, r)
+	}
+	for name, v := range m {
+		if child, ok := v.(map[string]any); ok && name != "entity" {
+			collect(child, records, lists)
+		}
+	}
+}
+
+func size(v any) int {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return len(b)
+}
@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// cache is an LRU cache of response bodies that expire after a TTL. It is
+// bounded by both the number of entries and their total size, and does not
+// keep bodies larger than maxEntry.
+type cache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	size     int
+	maxBytes int64
+	maxEntry int64
+	bytes    int64
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key     string
+	body    []byte
+	expires time.Time
+}
+
+func newCache(size int, maxBytes, maxEntry int64, ttl time.Duration) *cache {
+	return &cache{
+		ttl:      ttl,
+		size:     size,
+		maxBytes: maxBytes,
+		maxEntry: maxEntry,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (c *cache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if time.Now().After(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e.body, true
+}
+
+func (c *cache) put(key string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	if int64(len(body)) > c.maxEntry {
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, body: body, expires: time.Now().Add(c.ttl)})
+	c.bytes += int64(len(body))
+	for c.order.Len() > c.size || c.bytes > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops el from the cache. c.mu must be held.
+func (c *cache) remove(el *list.Element) {
+	e := c.order.Remove(el).(*cacheEntry)
+	delete(c.entries, e.key)
+	c.bytes -= int64(len(e.body))
+}
+
+// stats returns the number of cached responses and their total size.
+func (c *cache) stats() (int, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len(), c.bytes
+}
@@ -0,0 +1,63 @@
+package proxy
+
+import "sync"
+
+// Usage counts the requests of one caller.
+type Usage struct {
+	// Requests is the number of requests received.
+	Requests int64 `json:"requests"`
+	// CacheHits is the number of requests answered from the cache.
+	CacheHits int64 `json:"cache_hits"`
+	// Coalesced is the number of requests that shared another caller's upstream request.
+	Coalesced int64 `json:"coalesced"`
+	// Upstream is the number of requests forwarded to OpenAlex.
+	Upstream int64 `json:"upstream"`
+	// Errors is the number of requests answered with an error.
+	Errors int64 `json:"errors"`
+	// Bytes is the number of response body bytes sent.
+	Bytes int64 `json:"bytes"`
+}
+
+// Metrics is a snapshot of the proxy's usage.
+type Metrics struct {
+	Callers     map[string]Usage `json:"callers"`
+	Total       Usage            `json:"total"`
+	CachedItems int              `json:"cached_items"`
+	CachedBytes int64            `json:"cached_bytes"`
+}
+
+// usage tracks per-caller counters.
+type usage struct {
+	mu      sync.Mutex
+	callers map[string]*Usage
+}
+
+func (u *usage) record(caller string, fn func(*Usage)) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.callers == nil {
+		u.callers = make(map[string]*Usage)
+	}
+	c, ok := u.callers[caller]
+	if !ok {
+		c = &Usage{}
+		u.callers[caller] = c
+	}
+	fn(c)
+}
+
+func (u *usage) snapshot() Metrics {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	m := Metrics{Callers: make(map[string]Usage, len(u.callers))}
+	for name, c := range u.callers {
+		m.Callers[name] = *c
+		m.Total.Requests += c.Requests
+		m.Total.CacheHits += c.CacheHits
+		m.Total.Coalesced += c.Coalesced
+		m.Total.Upstream += c.Upstream
+		m.Total.Errors += c.Errors
+		m.Total.Bytes += c.Bytes
+	}
+	return m
+}
@@ -0,0 +1,254 @@
+// Package proxy implements a caching reverse proxy for the OpenAlex REST API,
+// so that several services can share one API key and one rate limit.
+//
+// Requests are forwarded through a core.Client, which adds the proxy's
+// polite-pool email and API key and retries transient failures. Successful
+// responses are cached, identical concurrent requests share one upstream
+// request, and usage is counted per caller.
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sunhill666/goalex/internal/ratelimit"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// Defaults applied by New.
+const (
+	DefaultCacheTTL     = time.Hour
+	DefaultCacheSize    = 10000
+	DefaultCacheBytes   = 256 << 20
+	DefaultEntryBytes   = 8 << 20
+	DefaultRateLimit    = 10
+	DefaultBurst        = 10
+	DefaultCallerHeader = "X-Goalex-Caller"
+)
+
+// MetricsPath serves the usage metrics as JSON.
+const MetricsPath = "/_proxy/metrics"
+
+// Values of the X-Cache response header.
+const (
+	CacheHit       = "HIT"
+	CacheMiss      = "MISS"
+	CacheCoalesced = "COALESCED"
+)
+
+// Options configures a Proxy.
+type Options struct {
+	// CacheTTL is how long responses are served from the cache. Defaults to DefaultCacheTTL.
+	CacheTTL time.Duration
+	// CacheSize is the maximum number of cached responses. Defaults to DefaultCacheSize.
+	CacheSize int
+	// CacheBytes is the maximum total size of the cached response bodies.
+	// Defaults to DefaultCacheBytes.
+	CacheBytes int64
+	// EntryBytes is the size above which a response body is not cached.
+	// Defaults to DefaultEntryBytes, and is at most CacheBytes.
+	EntryBytes int64
+	// RateLimit is the number of upstream requests allowed per second across
+	// all callers, retries included. Defaults to DefaultRateLimit; a negative
+	// value disables rate limiting.
+	RateLimit float64
+	// Burst is the number of upstream requests allowed at once. Defaults to DefaultBurst.
+	Burst int
+	// CallerHeader names the request header identifying the caller in the
+	// metrics. Callers without it are identified by their mailto parameter,
+	// then by their IP address. Defaults to DefaultCallerHeader.
+	CallerHeader string
+}
+
+// Proxy is an http.Handler that serves the OpenAlex REST API from a cache
+// backed by its own upstream client.
+type Proxy struct {
+	client       *core.Client
+	cache        *cache
+	usage        usage
+	callerHeader string
+}
+
+// New returns a proxy forwarding to OpenAlex through a client built from
+// clientOpts, such as core.PolitePool and core.Auth. The client coalesces
+// identical concurrent requests, and waits for the proxy's rate limiter before
+// every attempt, outside the HTTP client's timeout.
+func New(opts Options, clientOpts ...core.Option) *Proxy {
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	if opts.CacheSize <= 0 {
+		opts.CacheSize = DefaultCacheSize
+	}
+	if opts.CacheBytes <= 0 {
+		opts.CacheBytes = DefaultCacheBytes
+	}
+	if opts.EntryBytes <= 0 {
+		opts.EntryBytes = DefaultEntryBytes
+	}
+	opts.EntryBytes = min(opts.EntryBytes, opts.CacheBytes)
+	if opts.RateLimit == 0 {
+		opts.RateLimit = DefaultRateLimit
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = DefaultBurst
+	}
+	if opts.CallerHeader == "" {
+		opts.CallerHeader = DefaultCallerHeader
+	}
+
+	limiter := ratelimit.New(opts.RateLimit, opts.Burst)
+	client := core.New(append(slices.Clone(clientOpts), core.WithRequestCoalescing(), core.WithMiddleware(limit(limiter)))...)
+
+	return &Proxy{
+		client:       client,
+		cache:        newCache(opts.CacheSize, opts.CacheBytes, opts.EntryBytes, opts.CacheTTL),
+		callerHeader: opts.CallerHeader,
+	}
+}
+
+// limit returns middleware that waits for limiter before every upstream
+// attempt. As middleware runs before the HTTP client is called, the wait does
+// not count against the client's timeout.
+func limit(limiter *ratelimit.Limiter) core.Middleware {
+	return func(next core.Doer) core.Doer {
+		return core.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if sent, ok := req.Context().Value(sentKey{}).(*atomic.Bool); ok {
+				sent.Store(true)
+			}
+			if err := limiter.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// Metrics returns a snapshot of the usage counters.
+func (p *Proxy) Metrics() Metrics {
+	m := p.usage.snapshot()
+	m.CachedItems, m.CachedBytes = p.cache.stats()
+	return m
+}
+
+// ServeHTTP answers GET and HEAD requests for OpenAlex API paths, and serves
+// the usage metrics at MetricsPath.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == MetricsPath {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(p.Metrics())
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	caller := p.caller(r)
+	p.usage.record(caller, func(u *Usage) { u.Requests++ })
+
+	body, source, err := p.fetch(r.Context(), upstreamPath(r.URL))
+	if err != nil {
+		p.usage.record(caller, func(u *Usage) { u.Errors++ })
+		writeError(w, statusFor(err), err.Error())
+		return
+	}
+	p.usage.record(caller, func(u *Usage) {
+		switch source {
+		case CacheHit:
+			u.CacheHits++
+		case CacheCoalesced:
+			u.Coalesced++
+		default:
+			u.Upstream++
+		}
+		if r.Method == http.MethodGet {
+			u.Bytes += int64(len(body))
+		}
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Cache", source)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
+
+// sentKey marks the context of a proxied request with a flag set when an
+// upstream attempt is made on its behalf.
+type sentKey struct{}
+
+// fetch returns the body for path from the cache or from the upstream client,
+// and reports where it came from. The client runs a coalesced request with the
+// context of the caller that started it, so only that caller's flag is set;
+// the callers that joined it are reported as coalesced.
+func (p *Proxy) fetch(ctx context.Context, path string) ([]byte, string, error) {
+	if body, ok := p.cache.get(path); ok {
+		return body, CacheHit, nil
+	}
+
+	sent := new(atomic.Bool)
+	var raw json.RawMessage
+	if err := p.client.GetWithContext(context.WithValue(ctx, sentKey{}, sent), path, &raw); err != nil {
+		return nil, "", err
+	}
+	p.cache.put(path, raw)
+	if !sent.Load() {
+		return raw, CacheCoalesced, nil
+	}
+	return raw, CacheMiss, nil
+}
+
+// upstreamPath canonicalizes a request URL into the cache key and upstream
+// path. The caller's own mailto and api_key are dropped in favour of the
+// proxy's, and query parameters are sorted.
+func upstreamPath(u *url.URL) string {
+	q := u.Query()
+	q.Del("mailto")
+	q.Del("api_key")
+	if len(q) == 0 {
+		return u.EscapedPath()
+	}
+	return u.EscapedPath() + "?" + q.Encode()
+}
+
+func (p *Proxy) caller(r *http.Request) string {
+	if c := r.Header.Get(p.callerHeader); c != "" {
+		return c
+	}
+	if c := r.URL.Query().Get("mailto"); c != "" {
+		return c
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func statusFor(err error) int {
+	var apiErr *core.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr.StatusCode
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// writeError answers with an error body shaped like OpenAlex's own.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": http.StatusText(status), "message": message})
+}
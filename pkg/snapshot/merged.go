@@ -0,0 +1,151 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// MergedIDs maps the IDs of merged entities to the entities they were merged
+// into, as published in the snapshot's merged_ids files. It lets ID-keyed data
+// be remapped offline without a request per ID.
+type MergedIDs struct {
+	into  map[string]string
+	dates map[string]string
+}
+
+// NewMergedIDs returns an empty resolver.
+func NewMergedIDs() *MergedIDs {
+	return &MergedIDs{into: make(map[string]string), dates: make(map[string]string)}
+}
+
+// Add records that id was merged into another ID on the given date.
+func (m *MergedIDs) Add(id, into, date string) {
+	id, into = core.ShortID(id), core.ShortID(into)
+	if id == "" || into == "" || id == into {
+		return
+	}
+	m.into[id] = into
+	if date != "" {
+		m.dates[id] = date
+	}
+}
+
+// Len returns the number of merged IDs.
+func (m *MergedIDs) Len() int {
+	return len(m.into)
+}
+
+// IsMerged reports whether id is a tombstone that was merged into another entity.
+func (m *MergedIDs) IsMerged(id string) bool {
+	_, ok := m.into[core.ShortID(id)]
+	return ok
+}
+
+// MergeDate returns the date on which id was merged, if known.
+func (m *MergedIDs) MergeDate(id string) (string, bool) {
+	date, ok := m.dates[core.ShortID(id)]
+	return date, ok
+}
+
+// Resolve follows merges from id to the surviving entity. The result keeps
+// the form of id, short or URL. ok is false when id was never merged.
+func (m *MergedIDs) Resolve(id string) (string, bool) {
+	current := core.ShortID(id)
+	seen := map[string]bool{current: true}
+	for {
+		next, ok := m.into[current]
+		if !ok || seen[next] {
+			break
+		}
+		seen[next] = true
+		current = next
+	}
+	if current == core.ShortID(id) {
+		return id, false
+	}
+	if strings.Contains(id, "openalex.org/") {
+		return "https://openalex.org/" + current, true
+	}
+	return current, true
+}
+
+// Load reads a merged_ids CSV file with the columns merge_date, id and
+// merge_into_id. Gzipped input is detected automatically.
+func (m *MergedIDs) Load(r io.Reader) error {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	} else {
+		r = br
+	}
+
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	dateCol, idCol, intoCol := slices.Index(header, "merge_date"), slices.Index(header, "id"), slices.Index(header, "merge_into_id")
+	if idCol < 0 || intoCol < 0 {
+		return fmt.Errorf("merged_ids file lacks id and merge_into_id columns: %v", header)
+	}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		date := ""
+		if dateCol >= 0 {
+			date = record[dateCol]
+		}
+		m.Add(record[idCol], record[intoCol], date)
+	}
+}
+
+// MergedIDs loads every merged_ids file of an entity, found under
+// data/merged_ids/<entity>. A snapshot without merged IDs yields an empty resolver.
+func (s *Snapshot) MergedIDs(entity string) (*MergedIDs, error) {
+	m := NewMergedIDs()
+	paths, err := filepath.Glob(filepath.Join(s.data, "merged_ids", entity, "*.csv*"))
+	if err != nil {
+		return nil, err
+	}
+	// Files are named by date, so later merges are applied last.
+	slices.Sort(paths)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = m.Load(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return m, nil
+}
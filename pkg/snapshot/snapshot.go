@@ -0,0 +1,391 @@
+// Package snapshot reads a local copy of the OpenAlex snapshot, which stores
+// each entity as gzipped JSON Lines partitions under
+// data/<entity>/updated_date=<date>/part_<n>.gz together with a manifest.
+package snapshot
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// Entity directory names in the snapshot, derived from the API endpoints.
+var (
+	EntityWorks        = entity(core.EndpointWorks)
+	EntityAuthors      = entity(core.EndpointAuthors)
+	EntitySources      = entity(core.EndpointSources)
+	EntityInstitutions = entity(core.EndpointInstitutions)
+	EntityTopics       = entity(core.EndpointTopics)
+	EntityKeywords     = entity(core.EndpointKeywords)
+	EntityPublishers   = entity(core.EndpointPublishers)
+	EntityFunders      = entity(core.EndpointFunders)
+	EntityConcepts     = entity(core.EndpointConcepts)
+)
+
+func entity(endpoint string) string {
+	return strings.TrimPrefix(endpoint, "/")
+}
+
+// Snapshot is a local OpenAlex snapshot directory.
+type Snapshot struct {
+	data string
+}
+
+// Open opens a snapshot rooted at dir. dir may be the snapshot root, which
+// contains a data directory, or the data directory itself.
+func Open(dir string) (*Snapshot, error) {
+	data := filepath.Join(dir, "data")
+	if info, err := os.Stat(data); err != nil || !info.IsDir() {
+		data = dir
+	}
+	info, err := os.Stat(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("snapshot %s is not a directory", dir)
+	}
+	return &Snapshot{data: data}, nil
+}
+
+// Entities lists the entity directories present in the snapshot.
+func (s *Snapshot) Entities() ([]string, error) {
+	entries, err := os.ReadDir(s.data)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// Manifest describes the partitions of one entity.
+type Manifest struct {
+	Entries []*ManifestEntry `json:"entries"`
+	Meta    ManifestMeta     `json:"meta"`
+}
+
+// ManifestEntry is a single partition file listed in a manifest.
+type ManifestEntry struct {
+	URL  string       `json:"url"`
+	Meta ManifestMeta `json:"meta"`
+}
+
+// ManifestMeta holds the sizes recorded for a partition or a whole entity.
+type ManifestMeta struct {
+	ContentLength int64 `json:"content_length"`
+	RecordCount   int   `json:"record_count"`
+}
+
+// ErrNoManifest is returned when an entity directory has no manifest file.
+var ErrNoManifest = errors.New("snapshot manifest not found")
+
+// Manifest reads the manifest of an entity.
+func (s *Snapshot) Manifest(entity string) (*Manifest, error) {
+	f, err := os.Open(filepath.Join(s.data, entity, "manifest"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", entity, ErrNoManifest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	var m Manifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid manifest for %s: %w", entity, err)
+	}
+	return &m, nil
+}
+
+// Path maps a manifest URL such as s3://openalex/data/works/updated_date=2024-01-01/part_000.gz
+// to the matching file in the local snapshot.
+func (s *Snapshot) Path(url string) string {
+	_, rel, ok := strings.Cut(url, "/data/")
+	if !ok {
+		rel = url
+	}
+	return filepath.Join(s.data, filepath.FromSlash(rel))
+}
+
+// Partition is a single gzipped JSON Lines file of an entity.
+type Partition struct {
+	Path        string
+	UpdatedDate string
+	// RecordCount is the count from the manifest, or -1 when unknown.
+	RecordCount int
+}
+
+// Partitions lists the partition files of an entity, using the manifest when
+// present and the directory layout otherwise.
+func (s *Snapshot) Partitions(entity string) ([]*Partition, error) {
+	m, err := s.Manifest(entity)
+	if err != nil && !errors.Is(err, ErrNoManifest) {
+		return nil, err
+	}
+	var parts []*Partition
+	if m != nil {
+		for _, e := range m.Entries {
+			path := s.Path(e.URL)
+			parts = append(parts, &Partition{Path: path, UpdatedDate: updatedDate(path), RecordCount: e.Meta.RecordCount})
+		}
+		return parts, nil
+	}
+	paths, err := s.files(entity)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		parts = append(parts, &Partition{Path: path, UpdatedDate: updatedDate(path), RecordCount: -1})
+	}
+	return parts, nil
+}
+
+// files globs the partition files present on disk for an entity.
+func (s *Snapshot) files(entity string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.data, entity, "updated_date=*", "*.gz"))
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+func updatedDate(path string) string {
+	dir := filepath.Base(filepath.Dir(path))
+	date, _ := strings.CutPrefix(dir, "updated_date=")
+	if date == dir {
+		return ""
+	}
+	return date
+}
+
+// Options configures Read.
+type Options struct {
+	// Workers is the number of partitions decompressed in parallel. Defaults to GOMAXPROCS.
+	Workers int
+	// Since skips partitions whose updated_date is before this date (YYYY-MM-DD).
+	Since string
+}
+
+// Read streams the records of an entity decoded as T. Partitions are
+// decompressed in parallel, so records from different partitions interleave.
+// Breaking out of the loop stops the workers.
+func Read[T any](ctx context.Context, s *Snapshot, entity string, opts Options) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		parts, err := s.Partitions(entity)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if opts.Since != "" {
+			parts = slices.DeleteFunc(parts, func(p *Partition) bool {
+				return p.UpdatedDate != "" && p.UpdatedDate < opts.Since
+			})
+		}
+		workers := opts.Workers
+		if workers <= 0 {
+			workers = runtime.GOMAXPROCS(0)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type result struct {
+			record *T
+			err    error
+		}
+		jobs := make(chan *Partition)
+		results := make(chan result, workers*64)
+		var wg sync.WaitGroup
+		for range min(workers, max(len(parts), 1)) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for p := range jobs {
+					err := decodePartition(ctx, p.Path, func(v *T) bool {
+						select {
+						case results <- result{record: v}:
+							return true
+						case <-ctx.Done():
+							return false
+						}
+					})
+					if err != nil {
+						select {
+						case results <- result{err: err}:
+						case <-ctx.Done():
+						}
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			defer close(jobs)
+			for _, p := range parts {
+				select {
+				case jobs <- p:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		for r := range results {
+			if !yield(r.record, r.err) || r.err != nil {
+				cancel()
+				// Drain so that blocked workers can exit.
+				for range results {
+				}
+				return
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// decodePartition decodes every JSON line of a gzipped partition, calling fn
+// for each record until it returns false.
+func decodePartition[T any](ctx context.Context, path string, fn func(*T) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	dec := json.NewDecoder(gz)
+	for {
+		if ctx.Err() != nil {
+			return nil
+		}
+		var v T
+		if err := dec.Decode(&v); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if !fn(&v) {
+			return nil
+		}
+	}
+}
+
+// countRecords counts the records of a gzipped partition without decoding
+// them. Like the reader, it skips blank and whitespace-only lines.
+func countRecords(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(bufio.NewReader(f))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = gz.Close() }()
+
+	count := 0
+	// content reports whether the current line has anything but whitespace.
+	content := false
+	buf := make([]byte, 256*1024)
+	for {
+		n, err := gz.Read(buf)
+		for _, b := range buf[:n] {
+			switch b {
+			case '\n':
+				if content {
+					count++
+				}
+				content = false
+			case ' ', '\t', '\r':
+			default:
+				content = true
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+	}
+	// The final record may lack a trailing newline.
+	if content {
+		count++
+	}
+	return count, nil
+}
+
+// Works streams the works of the snapshot.
+func (s *Snapshot) Works(ctx context.Context, opts Options) iter.Seq2[*model.Work, error] {
+	return Read[model.Work](ctx, s, EntityWorks, opts)
+}
+
+// Authors streams the authors of the snapshot.
+func (s *Snapshot) Authors(ctx context.Context, opts Options) iter.Seq2[*model.Author, error] {
+	return Read[model.Author](ctx, s, EntityAuthors, opts)
+}
+
+// Sources streams the sources of the snapshot.
+func (s *Snapshot) Sources(ctx context.Context, opts Options) iter.Seq2[*model.Source, error] {
+	return Read[model.Source](ctx, s, EntitySources, opts)
+}
+
+// Institutions streams the institutions of the snapshot.
+func (s *Snapshot) Institutions(ctx context.Context, opts Options) iter.Seq2[*model.Institution, error] {
+	return Read[model.Institution](ctx, s, EntityInstitutions, opts)
+}
+
+// Topics streams the topics of the snapshot.
+func (s *Snapshot) Topics(ctx context.Context, opts Options) iter.Seq2[*model.Topic, error] {
+	return Read[model.Topic](ctx, s, EntityTopics, opts)
+}
+
+// Keywords streams the keywords of the snapshot.
+func (s *Snapshot) Keywords(ctx context.Context, opts Options) iter.Seq2[*model.Keyword, error] {
+	return Read[model.Keyword](ctx, s, EntityKeywords, opts)
+}
+
+// Publishers streams the publishers of the snapshot.
+func (s *Snapshot) Publishers(ctx context.Context, opts Options) iter.Seq2[*model.Publisher, error] {
+	return Read[model.Publisher](ctx, s, EntityPublishers, opts)
+}
+
+// Funders streams the funders of the snapshot.
+func (s *Snapshot) Funders(ctx context.Context, opts Options) iter.Seq2[*model.Funder, error] {
+	return Read[model.Funder](ctx, s, EntityFunders, opts)
+}
+
+// Concepts streams the concepts of the snapshot.
+func (s *Snapshot) Concepts(ctx context.Context, opts Options) iter.Seq2[*model.Concept, error] {
+	return Read[model.Concept](ctx, s, EntityConcepts, opts)
+}
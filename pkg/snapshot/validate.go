@@ -0,0 +1,120 @@
+package snapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// PartitionReport compares one manifest entry with the file on disk.
+type PartitionReport struct {
+	Path     string
+	Expected int
+	Actual   int
+	Missing  bool
+	Err      error
+}
+
+// OK reports whether the partition exists and matches its manifest count.
+func (p *PartitionReport) OK() bool {
+	return !p.Missing && p.Err == nil && p.Expected == p.Actual
+}
+
+// Report is the result of validating an entity against its manifest.
+type Report struct {
+	Entity     string
+	Expected   int
+	Actual     int
+	Partitions []*PartitionReport
+	// Unlisted holds partition files found on disk but absent from the manifest.
+	Unlisted []string
+}
+
+// OK reports whether every partition matches the manifest and no extra files exist.
+func (r *Report) OK() bool {
+	if len(r.Unlisted) > 0 || r.Expected != r.Actual {
+		return false
+	}
+	for _, p := range r.Partitions {
+		if !p.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// Problems returns a human-readable line for every mismatch.
+func (r *Report) Problems() []string {
+	var problems []string
+	for _, p := range r.Partitions {
+		switch {
+		case p.Missing:
+			problems = append(problems, fmt.Sprintf("%s: missing", p.Path))
+		case p.Err != nil:
+			problems = append(problems, fmt.Sprintf("%s: %v", p.Path, p.Err))
+		case p.Expected != p.Actual:
+			problems = append(problems, fmt.Sprintf("%s: expected %d records, found %d", p.Path, p.Expected, p.Actual))
+		}
+	}
+	for _, path := range r.Unlisted {
+		problems = append(problems, fmt.Sprintf("%s: not listed in manifest", path))
+	}
+	return problems
+}
+
+// Validate counts the records of every partition of an entity in parallel
+// and compares them with the manifest.
+func (s *Snapshot) Validate(ctx context.Context, entity string) (*Report, error) {
+	m, err := s.Manifest(entity)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Entity: entity, Expected: m.Meta.RecordCount}
+	listed := make(map[string]bool, len(m.Entries))
+	for _, e := range m.Entries {
+		path := s.Path(e.URL)
+		listed[path] = true
+		report.Partitions = append(report.Partitions, &PartitionReport{Path: path, Expected: e.Meta.RecordCount})
+	}
+
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for _, p := range report.Partitions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				p.Err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+			p.Actual, p.Err = countRecords(p.Path)
+			if errors.Is(p.Err, os.ErrNotExist) {
+				p.Missing, p.Err = true, nil
+			}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, p := range report.Partitions {
+		report.Actual += p.Actual
+	}
+	files, err := s.files(entity)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		if !listed[path] {
+			report.Unlisted = append(report.Unlisted, path)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,164 @@
+// Package store materializes OpenAlex entities into a local SQLite database
+// with normalized tables and keeps it current with incremental syncs.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// schema creates the normalized tables. Every entity table keeps the full JSON
+// document in data; it is NULL for rows only known from a dehydrated reference.
+const schema = `
+CREATE TABLE IF NOT EXISTS works (
+	id               TEXT PRIMARY KEY,
+	doi              TEXT,
+	display_name     TEXT,
+	publication_year INTEGER,
+	publication_date TEXT,
+	type             TEXT,
+	language         TEXT,
+	cited_by_count   INTEGER,
+	fwci             REAL,
+	is_oa            INTEGER,
+	oa_status        TEXT,
+	source_id        TEXT,
+	primary_topic_id TEXT,
+	updated_date     TEXT,
+	data             TEXT
+);
+CREATE TABLE IF NOT EXISTS authors (
+	id             TEXT PRIMARY KEY,
+	display_name   TEXT,
+	orcid          TEXT,
+	works_count    INTEGER,
+	cited_by_count INTEGER,
+	updated_date   TEXT,
+	data           TEXT
+);
+CREATE TABLE IF NOT EXISTS institutions (
+	id             TEXT PRIMARY KEY,
+	display_name   TEXT,
+	ror            TEXT,
+	country_code   TEXT,
+	type           TEXT,
+	works_count    INTEGER,
+	cited_by_count INTEGER,
+	updated_date   TEXT,
+	data           TEXT
+);
+CREATE TABLE IF NOT EXISTS sources (
+	id             TEXT PRIMARY KEY,
+	display_name   TEXT,
+	issn_l         TEXT,
+	type           TEXT,
+	works_count    INTEGER,
+	cited_by_count INTEGER,
+	updated_date   TEXT,
+	data           TEXT
+);
+CREATE TABLE IF NOT EXISTS topics (
+	id           TEXT PRIMARY KEY,
+	display_name TEXT,
+	subfield_id  TEXT,
+	field_id     TEXT,
+	domain_id    TEXT,
+	works_count  INTEGER,
+	updated_date TEXT,
+	data         TEXT
+);
+CREATE TABLE IF NOT EXISTS authorships (
+	work_id          TEXT NOT NULL REFERENCES works(id) ON DELETE CASCADE,
+	position         INTEGER NOT NULL,
+	author_id        TEXT,
+	author_position  TEXT,
+	is_corresponding INTEGER,
+	raw_author_name  TEXT,
+	PRIMARY KEY (work_id, position)
+);
+CREATE INDEX IF NOT EXISTS authorships_author ON authorships(author_id);
+CREATE TABLE IF NOT EXISTS authorship_institutions (
+	work_id        TEXT NOT NULL,
+	position       INTEGER NOT NULL,
+	institution_id TEXT NOT NULL,
+	PRIMARY KEY (work_id, position, institution_id),
+	FOREIGN KEY (work_id, position) REFERENCES authorships(work_id, position) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS authorship_institutions_institution ON authorship_institutions(institution_id);
+CREATE TABLE IF NOT EXISTS work_topics (
+	work_id    TEXT NOT NULL REFERENCES works(id) ON DELETE CASCADE,
+	topic_id   TEXT NOT NULL,
+	score      REAL,
+	is_primary INTEGER,
+	PRIMARY KEY (work_id, topic_id)
+);
+CREATE TABLE IF NOT EXISTS work_references (
+	work_id            TEXT NOT NULL REFERENCES works(id) ON DELETE CASCADE,
+	referenced_work_id TEXT NOT NULL,
+	PRIMARY KEY (work_id, referenced_work_id)
+);
+CREATE INDEX IF NOT EXISTS work_references_referenced ON work_references(referenced_work_id);
+CREATE TABLE IF NOT EXISTS sync_state (
+	name      TEXT PRIMARY KEY,
+	last_sync TEXT NOT NULL
+);
+`
+
+// Store is a local SQLite mirror of OpenAlex entities.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens or creates the SQLite database at path and ensures the schema exists.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; sharing one connection avoids SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+	s, err := New(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// New wraps an existing SQLite connection and ensures the schema exists.
+func New(db *sql.DB) (*Store, error) {
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// DB returns the underlying database for ad-hoc queries.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// withTx runs fn in a transaction, committing on success.
+func (s *Store) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// SyncResult summarizes a Sync run.
+type SyncResult struct {
+	// Since is the from_updated_date filter applied, empty on the first sync.
+	Since string
+	// Fetched is the number of records returned by the API.
+	Fetched int
+	// Changed is the number of records inserted or updated in the store.
+	Changed int
+	// Started is recorded as the last sync time once the run completes.
+	Started time.Time
+}
+
+// LastSync returns the time of the last completed sync with the given name.
+func (s *Store) LastSync(ctx context.Context, name string) (time.Time, bool, error) {
+	var last string
+	err := s.db.QueryRowContext(ctx, "SELECT last_sync FROM sync_state WHERE name = ?", name).Scan(&last)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	t, err := time.Parse(time.RFC3339, last)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid last sync time %q: %w", last, err)
+	}
+	return t, true, nil
+}
+
+func (s *Store) setLastSync(ctx context.Context, name string, t time.Time) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO sync_state (name, last_sync) VALUES (?, ?)
+ON CONFLICT(name) DO UPDATE SET last_sync = excluded.last_sync`, name, t.UTC().Format(time.RFC3339))
+	return err
+}
+
+// Sync harvests q into the store with cursor pagination. name identifies the
+// query; after the first run only records updated since the previous
+// successful run are requested through the from_updated_date filter, which
+// OpenAlex only honours for requests made with an API key. q itself is not
+// changed, so it can be synced again.
+//
+// Supported entity types are Work, Author, Institution, Source and Topic.
+func Sync[T any](ctx context.Context, s *Store, name string, q *core.QueryBuilder[T]) (*SyncResult, error) {
+	q = q.Clone()
+	upsert, err := upserterFor[T](s)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &SyncResult{Started: time.Now().UTC()}
+	last, ok, err := s.LastSync(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		res.Since = last.Format(time.RFC3339)
+		q.Filter("from_updated_date", res.Since)
+	}
+	q.PerPage(200)
+
+	cursor := "*"
+	for cursor != "" {
+		results, next, err := q.CursorWithContext(ctx, cursor)
+		if err != nil {
+			return res, err
+		}
+		changed, err := upsert(ctx, results)
+		if err != nil {
+			return res, err
+		}
+		res.Fetched += len(results)
+		res.Changed += changed
+		if len(results) == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	if err := s.setLastSync(ctx, name, res.Started); err != nil {
+		return res, err
+	}
+	return res, nil
+}
+
+func upserterFor[T any](s *Store) (func(context.Context, []*T) (int, error), error) {
+	var f any
+	switch any((*T)(nil)).(type) {
+	case *model.Work:
+		f = func(ctx context.Context, v []*model.Work) (int, error) { return s.UpsertWorks(ctx, v...) }
+	case *model.Author:
+		f = func(ctx context.Context, v []*model.Author) (int, error) { return s.UpsertAuthors(ctx, v...) }
+	case *model.Institution:
+		f = func(ctx context.Context, v []*model.Institution) (int, error) { return s.UpsertInstitutions(ctx, v...) }
+	case *model.Source:
+		f = func(ctx context.Context, v []*model.Source) (int, error) { return s.UpsertSources(ctx, v...) }
+	case *model.Topic:
+		f = func(ctx context.Context, v []*model.Topic) (int, error) { return s.UpsertTopics(ctx, v...) }
+	default:
+		return nil, fmt.Errorf("store does not support %T", (*T)(nil))
+	}
+	return f.(func(context.Context, []*T) (int, error)), nil
+}
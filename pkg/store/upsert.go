@@ -0,0 +1,391 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// Each full upsert only replaces a row when the incoming updated_date is newer,
+// so replaying an older harvest never overwrites fresher data. Rows created
+// from dehydrated references have no updated_date and are always replaced.
+const (
+	upsertWork = `INSERT INTO works (id, doi, display_name, publication_year, publication_date, type, language,
+	cited_by_count, fwci, is_oa, oa_status, source_id, primary_topic_id, updated_date, data)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET doi = excluded.doi, display_name = excluded.display_name,
+	publication_year = excluded.publication_year, publication_date = excluded.publication_date,
+	type = excluded.type, language = excluded.language, cited_by_count = excluded.cited_by_count,
+	fwci = excluded.fwci, is_oa = excluded.is_oa, oa_status = excluded.oa_status,
+	source_id = excluded.source_id, primary_topic_id = excluded.primary_topic_id,
+	updated_date = excluded.updated_date, data = excluded.data
+WHERE works.updated_date IS NULL OR excluded.updated_date > works.updated_date`
+
+	upsertAuthor = `INSERT INTO authors (id, display_name, orcid, works_count, cited_by_count, updated_date, data)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET display_name = excluded.display_name, orcid = excluded.orcid,
+	works_count = excluded.works_count, cited_by_count = excluded.cited_by_count,
+	updated_date = excluded.updated_date, data = excluded.data
+WHERE authors.updated_date IS NULL OR excluded.updated_date > authors.updated_date`
+
+	upsertInstitution = `INSERT INTO institutions (id, display_name, ror, country_code, type, works_count,
+	cited_by_count, updated_date, data)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET display_name = excluded.display_name, ror = excluded.ror,
+	country_code = excluded.country_code, type = excluded.type, works_count = excluded.works_count,
+	cited_by_count = excluded.cited_by_count, updated_date = excluded.updated_date, data = excluded.data
+WHERE institutions.updated_date IS NULL OR excluded.updated_date > institutions.updated_date`
+
+	upsertSource = `INSERT INTO sources (id, display_name, issn_l, type, works_count, cited_by_count, updated_date, data)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET display_name = excluded.display_name, issn_l = excluded.issn_l,
+	type = excluded.type, works_count = excluded.works_count, cited_by_count = excluded.cited_by_count,
+	updated_date = excluded.updated_date, data = excluded.data
+WHERE sources.updated_date IS NULL OR excluded.updated_date > sources.updated_date`
+
+	upsertTopic = `INSERT INTO topics (id, display_name, subfield_id, field_id, domain_id, works_count, updated_date, data)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT(id) DO UPDATE SET display_name = excluded.display_name, subfield_id = excluded.subfield_id,
+	field_id = excluded.field_id, domain_id = excluded.domain_id, works_count = excluded.works_count,
+	updated_date = excluded.updated_date, data = excluded.data
+WHERE topics.updated_date IS NULL OR excluded.updated_date > topics.updated_date`
+
+	insertAuthorRef      = `INSERT INTO authors (id, display_name, orcid) VALUES (?, ?, ?) ON CONFLICT(id) DO NOTHING`
+	insertInstitutionRef = `INSERT INTO institutions (id, display_name, ror, country_code, type) VALUES (?, ?, ?, ?, ?) ON CONFLICT(id) DO NOTHING`
+	insertSourceRef      = `INSERT INTO sources (id, display_name, issn_l, type) VALUES (?, ?, ?, ?) ON CONFLICT(id) DO NOTHING`
+	insertTopicRef       = `INSERT INTO topics (id, display_name, subfield_id, field_id, domain_id) VALUES (?, ?, ?, ?, ?) ON CONFLICT(id) DO NOTHING`
+)
+
+// ErrNotFound is returned when an entity is not in the store or only known
+// from a dehydrated reference.
+var ErrNotFound = errors.New("entity not found in store")
+
+// UpsertWorks stores works together with their authorships, topics and
+// references, and returns the number of works inserted or updated.
+func (s *Store) UpsertWorks(ctx context.Context, works ...*model.Work) (int, error) {
+	changed := 0
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, w := range works {
+			ok, err := upsertWorkTx(ctx, tx, w)
+			if err != nil {
+				return fmt.Errorf("work %s: %w", w.ID, err)
+			}
+			if ok {
+				changed++
+			}
+		}
+		return nil
+	})
+	return changed, err
+}
+
+func upsertWorkTx(ctx context.Context, tx *sql.Tx, w *model.Work) (bool, error) {
+	if w == nil || w.ID == "" {
+		return false, nil
+	}
+	data, err := json.Marshal(w)
+	if err != nil {
+		return false, err
+	}
+	var isOA sql.NullBool
+	var oaStatus string
+	if w.OpenAccess != nil {
+		isOA = sql.NullBool{Bool: w.OpenAccess.IsOA, Valid: true}
+		if w.OpenAccess.OAStatus != nil {
+			oaStatus = string(*w.OpenAccess.OAStatus)
+		}
+	}
+	var sourceID, primaryTopicID string
+	if w.PrimaryLocation != nil && w.PrimaryLocation.Source != nil {
+		sourceID = w.PrimaryLocation.Source.ID
+	}
+	if w.PrimaryTopic != nil {
+		primaryTopicID = w.PrimaryTopic.ID
+	}
+	res, err := tx.ExecContext(ctx, upsertWork, w.ID, nullString(w.DOI), nullString(w.DisplayName),
+		w.PublicationYear, nullString(w.PublicationDate), nullString(w.Type), nullString(w.Language),
+		w.CitedByCount, w.FWCI, isOA, nullString(oaStatus), nullString(sourceID), nullString(primaryTopicID),
+		nullString(w.UpdatedDate), string(data))
+	if err != nil {
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return false, err
+	}
+
+	// The work changed, so its child rows are rebuilt from scratch.
+	for _, table := range []string{"authorship_institutions", "authorships", "work_topics", "work_references"} {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE work_id = ?", w.ID); err != nil {
+			return false, err
+		}
+	}
+	for i, a := range w.Authorships {
+		if a == nil {
+			continue
+		}
+		if a.Author.ID != "" {
+			if _, err := tx.ExecContext(ctx, insertAuthorRef, a.Author.ID, nullString(a.Author.DisplayName), nullString(a.Author.ORCID)); err != nil {
+				return false, err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO authorships (work_id, position, author_id, author_position, is_corresponding, raw_author_name)
+VALUES (?, ?, ?, ?, ?, ?)`, w.ID, i, nullString(a.Author.ID), nullString(a.AuthorPosition), a.IsCorresponding, nullString(a.RawAuthorName)); err != nil {
+			return false, err
+		}
+		for _, inst := range a.Institution {
+			if inst == nil || inst.ID == "" {
+				continue
+			}
+			if err := insertInstitutionRefTx(ctx, tx, inst); err != nil {
+				return false, err
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO authorship_institutions (work_id, position, institution_id)
+VALUES (?, ?, ?)`, w.ID, i, inst.ID); err != nil {
+				return false, err
+			}
+		}
+	}
+	for _, loc := range []*model.Location{w.PrimaryLocation, w.BestOALocation} {
+		if loc != nil && loc.Source != nil && loc.Source.ID != "" {
+			src := loc.Source
+			if _, err := tx.ExecContext(ctx, insertSourceRef, src.ID, nullString(src.DisplayName), nullString(src.ISSNL), nullString(src.Type)); err != nil {
+				return false, err
+			}
+		}
+	}
+	for _, t := range w.Topics {
+		if t == nil || t.ID == "" {
+			continue
+		}
+		if err := insertTopicRefTx(ctx, tx, &t.Topic); err != nil {
+			return false, err
+		}
+		isPrimary := w.PrimaryTopic != nil && w.PrimaryTopic.ID == t.ID
+		if _, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO work_topics (work_id, topic_id, score, is_primary) VALUES (?, ?, ?, ?)`,
+			w.ID, t.ID, t.Score, isPrimary); err != nil {
+			return false, err
+		}
+	}
+	for _, ref := range w.ReferenceWorks {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO work_references (work_id, referenced_work_id) VALUES (?, ?)`, w.ID, ref); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func insertInstitutionRefTx(ctx context.Context, tx *sql.Tx, inst *model.DehydratedInstitution) error {
+	_, err := tx.ExecContext(ctx, insertInstitutionRef, inst.ID, nullString(inst.DisplayName), nullString(inst.ROR),
+		nullString(inst.CountryCode), nullString(inst.Type))
+	return err
+}
+
+func insertTopicRefTx(ctx context.Context, tx *sql.Tx, t *model.Topic) error {
+	subfield, field, domain := topicFieldIDs(t)
+	_, err := tx.ExecContext(ctx, insertTopicRef, t.ID, nullString(t.DisplayName), subfield, field, domain)
+	return err
+}
+
+func topicFieldIDs(t *model.Topic) (subfield, field, domain sql.NullString) {
+	if t.Subfield != nil {
+		subfield = nullString(t.Subfield.ID)
+	}
+	if t.Field != nil {
+		field = nullString(t.Field.ID)
+	}
+	if t.Domain != nil {
+		domain = nullString(t.Domain.ID)
+	}
+	return subfield, field, domain
+}
+
+// UpsertAuthors stores authors and returns the number inserted or updated.
+func (s *Store) UpsertAuthors(ctx context.Context, authors ...*model.Author) (int, error) {
+	return upsertAll(ctx, s, authors, func(a *model.Author) (string, []any) {
+		return a.ID, []any{a.ID, nullString(a.DisplayName), nullString(a.ORCID), a.WorksCount, a.CitedByCount, nullString(a.UpdatedDate)}
+	}, upsertAuthor)
+}
+
+// UpsertInstitutions stores institutions and returns the number inserted or updated.
+func (s *Store) UpsertInstitutions(ctx context.Context, institutions ...*model.Institution) (int, error) {
+	return upsertAll(ctx, s, institutions, func(i *model.Institution) (string, []any) {
+		return i.ID, []any{i.ID, nullString(i.DisplayName), nullString(i.ROR), nullString(i.CountryCode), nullString(i.Type),
+			i.WorksCount, i.CitedByCount, nullString(i.UpdatedDate)}
+	}, upsertInstitution)
+}
+
+// UpsertSources stores sources and returns the number inserted or updated.
+func (s *Store) UpsertSources(ctx context.Context, sources ...*model.Source) (int, error) {
+	return upsertAll(ctx, s, sources, func(src *model.Source) (string, []any) {
+		return src.ID, []any{src.ID, nullString(src.DisplayName), nullString(src.ISSNL), nullString(src.Type),
+			src.WorksCount, src.CitedByCount, nullString(src.UpdatedDate)}
+	}, upsertSource)
+}
+
+// UpsertTopics stores topics and returns the number inserted or updated.
+func (s *Store) UpsertTopics(ctx context.Context, topics ...*model.Topic) (int, error) {
+	return upsertAll(ctx, s, topics, func(t *model.Topic) (string, []any) {
+		subfield, field, domain := topicFieldIDs(t)
+		return t.ID, []any{t.ID, nullString(t.DisplayName), subfield, field, domain, t.WorksCount, nullString(t.UpdateDate)}
+	}, upsertTopic)
+}
+
+// upsertAll runs query for each entity with the columns returned by args
+// followed by the JSON document.
+func upsertAll[T any](ctx context.Context, s *Store, entities []*T, args func(*T) (string, []any), query string) (int, error) {
+	changed := 0
+	err := s.withTx(ctx, func(tx *sql.Tx) error {
+		for _, e := range entities {
+			if e == nil {
+				continue
+			}
+			id, values := args(e)
+			if id == "" {
+				continue
+			}
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+			res, err := tx.ExecContext(ctx, query, append(values, string(data))...)
+			if err != nil {
+				return fmt.Errorf("%s: %w", id, err)
+			}
+			if n, err := res.RowsAffected(); err != nil {
+				return err
+			} else if n > 0 {
+				changed++
+			}
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// Work returns the stored work with the given OpenAlex ID.
+func (s *Store) Work(ctx context.Context, id string) (*model.Work, error) {
+	return get[model.Work](ctx, s, "works", id)
+}
+
+// Author returns the stored author with the given OpenAlex ID.
+func (s *Store) Author(ctx context.Context, id string) (*model.Author, error) {
+	return get[model.Author](ctx, s, "authors", id)
+}
+
+// Institution returns the stored institution with the given OpenAlex ID.
+func (s *Store) Institution(ctx context.Context, id string) (*model.Institution, error) {
+	return get[model.Institution](ctx, s, "institutions", id)
+}
+
+// Source returns the stored source with the given OpenAlex ID.
+func (s *Store) Source(ctx context.Context, id string) (*model.Source, error) {
+	return get[model.Source](ctx, s, "sources", id)
+}
+
+// Topic returns the stored topic with the given OpenAlex ID.
+func (s *Store) Topic(ctx context.Context, id string) (*model.Topic, error) {
+	return get[model.Topic](ctx, s, "topics", id)
+}
+
+func get[T any](ctx context.Context, s *Store, table, id string) (*T, error) {
+	var data sql.NullString
+	err := s.db.QueryRowContext(ctx, "SELECT data FROM "+table+" WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !data.Valid) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entity T
+	// The stored JSON holds the fields kept in Extra when it was stored.
+	if err := core.UnmarshalWithExtra([]byte(data.String), &entity); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", id, err)
+	}
+	return &entity, nil
+}
+
+// WorkFilter narrows the works returned by Works. Zero fields match every
+// work, and IDs may be given in short or URL form.
+type WorkFilter struct {
+	// FromYear and ToYear bound the publication year, inclusive.
+	FromYear, ToYear int
+	Type             string
+	SourceID         string
+	AuthorID         string
+	InstitutionID    string
+	TopicID          string
+}
+
+// where returns the SQL condition on the works table for f, with its arguments.
+func (f WorkFilter) where() (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		conds = append(conds, cond)
+		args = append(args, arg)
+	}
+	if f.FromYear > 0 {
+		add("publication_year >= ?", f.FromYear)
+	}
+	if f.ToYear > 0 {
+		add("publication_year <= ?", f.ToYear)
+	}
+	if f.Type != "" {
+		add("type = ?", f.Type)
+	}
+	if f.SourceID != "" {
+		add("source_id = ?", entityURL(f.SourceID))
+	}
+	if f.AuthorID != "" {
+		add("id IN (SELECT work_id FROM authorships WHERE author_id = ?)", entityURL(f.AuthorID))
+	}
+	if f.InstitutionID != "" {
+		add("id IN (SELECT work_id FROM authorship_institutions WHERE institution_id = ?)", entityURL(f.InstitutionID))
+	}
+	if f.TopicID != "" {
+		add("id IN (SELECT work_id FROM work_topics WHERE topic_id = ?)", entityURL(f.TopicID))
+	}
+	return strings.Join(conds, " AND "), args
+}
+
+// entityURL returns native OpenAlex IDs in the URL form they are stored in.
+func entityURL(id string) string {
+	if core.IsOpenAlexID(id) {
+		return "https://openalex.org/" + core.ShortID(id)
+	}
+	return id
+}
+
+// Works returns the stored works that match f, ordered by ID.
+func (s *Store) Works(ctx context.Context, f WorkFilter) ([]*model.Work, error) {
+	query := "SELECT data FROM works WHERE data IS NOT NULL"
+	where, args := f.where()
+	if where != "" {
+		query += " AND " + where
+	}
+	rows, err := s.db.QueryContext(ctx, query+" ORDER BY id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var works []*model.Work
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var w model.Work
+		if err := core.UnmarshalWithExtra([]byte(data), &w); err != nil {
+			return nil, err
+		}
+		works = append(works, &w)
+	}
+	return works, rows.Err()
+}
@@ -0,0 +1,67 @@
+package suggest
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/Sunhill666/goalex/internal/model"
+)
+
+// cache is a size-bounded LRU cache of merged completions per query that
+// expire after a TTL.
+type cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	key         string
+	completions []*model.Completion
+	// complete reports whether every endpoint returned all of its matches, so
+	// that the completions of longer queries are a subset of these.
+	complete bool
+	expires  time.Time
+}
+
+func newCache(size int, ttl time.Duration) *cache {
+	return &cache{ttl: ttl, size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (c *cache) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if time.Now().After(e.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return e, true
+}
+
+func (c *cache) put(key string, completions []*model.Completion, complete bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expires := time.Now().Add(c.ttl)
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*cacheEntry)
+		e.completions, e.complete, e.expires = completions, complete, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, completions: completions, complete: complete, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
@@ -0,0 +1,265 @@
+// Package suggest implements autocomplete for interactive search boxes on top
+// of the OpenAlex autocomplete endpoints.
+//
+// A Session belongs to one user's search box. It debounces keystrokes,
+// cancels the requests of superseded input, answers repeated and narrowed
+// queries from a prefix cache, and merges and ranks the completions of
+// several entity endpoints.
+package suggest
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// Defaults applied by NewSession.
+const (
+	DefaultDebounce  = 150 * time.Millisecond
+	DefaultLimit     = 10
+	DefaultCacheSize = 256
+	DefaultCacheTTL  = 10 * time.Minute
+)
+
+// ErrSuperseded is returned by Suggest when a later call replaced its input
+// before it completed.
+var ErrSuperseded = errors.New("suggest: superseded by newer input")
+
+// Options configures a Session.
+type Options struct {
+	// Endpoints are the entity endpoints to complete, such as
+	// core.EndpointAuthors and core.EndpointInstitutions. Defaults to the
+	// global endpoint, which completes every entity type.
+	Endpoints []string
+	// Debounce is how long input must stay unchanged before it is sent.
+	// Defaults to 150ms; negative disables debouncing.
+	Debounce time.Duration
+	// MinLength is the number of characters below which Suggest returns no
+	// completions without a request. Defaults to 1.
+	MinLength int
+	// Limit is the maximum number of merged completions returned. Defaults to 10.
+	Limit int
+	// CacheSize is the number of queries whose completions are cached.
+	// Defaults to 256.
+	CacheSize int
+	// CacheTTL is how long cached completions are reused. Defaults to 10 minutes.
+	CacheTTL time.Duration
+}
+
+// Session serves the autocomplete requests of one search box. It is safe for
+// concurrent use; each call to Suggest supersedes the previous one.
+type Session struct {
+	client *core.Client
+	opts   Options
+	cache  *cache
+
+	mu         sync.Mutex
+	generation uint64
+	cancel     context.CancelFunc
+}
+
+// NewSession returns a session that completes through c.
+func NewSession(c *core.Client, opts Options) *Session {
+	if len(opts.Endpoints) == 0 {
+		opts.Endpoints = []string{""}
+	}
+	if opts.Debounce == 0 {
+		opts.Debounce = DefaultDebounce
+	}
+	if opts.MinLength <= 0 {
+		opts.MinLength = 1
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultLimit
+	}
+	if opts.CacheSize <= 0 {
+		opts.CacheSize = DefaultCacheSize
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultCacheTTL
+	}
+	return &Session{client: c, opts: opts, cache: newCache(opts.CacheSize, opts.CacheTTL)}
+}
+
+// Suggest returns the ranked completions of input. It cancels the previous
+// call, which then returns ErrSuperseded, and waits for the debounce interval
+// before sending a request. Cached queries, and narrower queries whose shorter
+// prefix was answered in full, are served from the cache without waiting.
+func (s *Session) Suggest(ctx context.Context, input string) ([]*model.Completion, error) {
+	q := normalize(input)
+
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.generation++
+	generation := s.generation
+	ctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+	s.mu.Unlock()
+	defer cancel()
+
+	if utf8.RuneCountInString(q) < s.opts.MinLength {
+		return []*model.Completion{}, nil
+	}
+	if completions, ok := s.cached(q); ok {
+		return s.rank(q, completions), nil
+	}
+
+	if s.opts.Debounce > 0 {
+		timer := time.NewTimer(s.opts.Debounce)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, s.canceled(ctx, generation)
+		}
+	}
+
+	completions, complete, err := s.fetch(ctx, q)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, s.canceled(ctx, generation)
+		}
+		return nil, err
+	}
+	s.cache.put(q, completions, complete)
+	return s.rank(q, completions), nil
+}
+
+// canceled returns ErrSuperseded when a newer call cancelled ctx and the
+// caller's context error otherwise.
+func (s *Session) canceled(ctx context.Context, generation uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return ErrSuperseded
+	}
+	return ctx.Err()
+}
+
+// cached returns the completions of q from the cache, or narrows those of
+// the longest shorter prefix for which OpenAlex reported every match. Narrowed
+// completions are not cached themselves, so they are always drawn from a
+// complete answer.
+func (s *Session) cached(q string) ([]*model.Completion, bool) {
+	if e, ok := s.cache.get(q); ok {
+		return e.completions, true
+	}
+	for i := len(q) - 1; i > 0; i-- {
+		if !utf8.RuneStart(q[i]) {
+			continue
+		}
+		e, ok := s.cache.get(q[:i])
+		if !ok || !e.complete {
+			continue
+		}
+		var narrowed []*model.Completion
+		for _, comp := range e.completions {
+			if matches(q, comp) {
+				narrowed = append(narrowed, comp)
+			}
+		}
+		return narrowed, true
+	}
+	return nil, false
+}
+
+// fetch queries every endpoint concurrently and merges their completions,
+// dropping duplicates. complete reports whether every endpoint returned all of
+// its matches, as counted by meta.count.
+func (s *Session) fetch(ctx context.Context, q string) ([]*model.Completion, bool, error) {
+	results := make([]*model.PaginatedResponse[model.Completion], len(s.opts.Endpoints))
+	errs := make([]error, len(s.opts.Endpoints))
+	var wg sync.WaitGroup
+	for i, endpoint := range s.opts.Endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := core.ListEntitiesWithContext[model.Completion](ctx, s.client, core.EndPointAutoComplete+endpoint, &core.QueryParams{AutoComplete: q})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i] = resp
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, false, err
+	}
+
+	complete := true
+	seen := make(map[string]bool)
+	var merged []*model.Completion
+	for _, resp := range results {
+		if resp.Meta == nil || resp.Meta.Count > len(resp.Results) {
+			complete = false
+		}
+		for _, comp := range resp.Results {
+			if seen[comp.ID] {
+				continue
+			}
+			seen[comp.ID] = true
+			merged = append(merged, comp)
+		}
+	}
+	return merged, complete, nil
+}
+
+// rank orders completions by how closely their names match q, then by
+// citations and works, and applies the limit. Completions that start with q
+// come first, then those with a word starting with q.
+func (s *Session) rank(q string, completions []*model.Completion) []*model.Completion {
+	ranked := slices.Clone(completions)
+	slices.SortStableFunc(ranked, func(a, b *model.Completion) int {
+		return cmp.Or(
+			cmp.Compare(tier(q, a), tier(q, b)),
+			cmp.Compare(b.CitedByCount, a.CitedByCount),
+			cmp.Compare(b.WorksCount, a.WorksCount),
+		)
+	})
+	if len(ranked) > s.opts.Limit {
+		ranked = ranked[:s.opts.Limit]
+	}
+	if ranked == nil {
+		ranked = []*model.Completion{}
+	}
+	return ranked
+}
+
+func tier(q string, comp *model.Completion) int {
+	name := normalize(comp.DisplayName)
+	switch {
+	case strings.HasPrefix(name, q):
+		return 0
+	case matches(q, comp):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// matches reports whether every word of q starts a word of the completion's
+// name or hint, which approximates how OpenAlex matches autocomplete queries.
+func matches(q string, comp *model.Completion) bool {
+	words := strings.Fields(normalize(comp.DisplayName + " " + comp.Hint))
+	for _, part := range strings.Fields(q) {
+		if !slices.ContainsFunc(words, func(w string) bool { return strings.HasPrefix(w, part) }) {
+			return false
+		}
+	}
+	return true
+}
+
+func normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
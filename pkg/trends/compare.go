@@ -0,0 +1,118 @@
+package trends
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Comparison holds several series aligned over the same years.
+type Comparison struct {
+	// From and To are the first and last years covered by any of the series.
+	From, To int
+	// Series are the compared series, each ranged over From to To.
+	Series []*Series
+
+	// sources are the series as given, covering only their own years.
+	sources []*Series
+}
+
+// Compare aligns series over the years covered by any of them, filling the
+// years a series does not cover with zero. Empty series are aligned too but
+// do not widen the range.
+func Compare(series ...*Series) *Comparison {
+	c := &Comparison{sources: series}
+	for _, s := range series {
+		if len(s.Points) == 0 {
+			continue
+		}
+		if c.From == 0 || s.First() < c.From {
+			c.From = s.First()
+		}
+		if s.Last() > c.To {
+			c.To = s.Last()
+		}
+	}
+	c.Series = make([]*Series, len(series))
+	for i, s := range series {
+		if c.From == 0 {
+			c.Series[i] = &Series{Name: s.Name}
+			continue
+		}
+		c.Series[i] = s.Range(c.From, c.To)
+	}
+	return c
+}
+
+// Values returns the value of every series in year, in order.
+func (c *Comparison) Values(year int) []float64 {
+	values := make([]float64, len(c.Series))
+	for i, s := range c.Series {
+		values[i] = s.Value(year)
+	}
+	return values
+}
+
+// Shares returns the share of every series in the total of all series in
+// year, in order. The shares are zero when the total is zero.
+func (c *Comparison) Shares(year int) []float64 {
+	values := c.Values(year)
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	if total == 0 {
+		return make([]float64, len(values))
+	}
+	for i := range values {
+		values[i] /= total
+	}
+	return values
+}
+
+// Ranking is the CAGR of one compared series.
+type Ranking struct {
+	Series *Series
+	CAGR   float64
+	// Err is ErrInsufficientData when the CAGR of the series is not defined,
+	// as for series that end at zero.
+	Err error
+}
+
+// RankByCAGR orders the series by compound annual growth rate, fastest
+// growing first. Each series is measured over the years it covers itself,
+// from its first year with a value other than zero, so that years filled in
+// by the alignment do not count. Series that end at zero, having collapsed,
+// have no defined CAGR; they and the other series without one come last in
+// their original order.
+func (c *Comparison) RankByCAGR() []*Ranking {
+	rankings := make([]*Ranking, len(c.Series))
+	for i, s := range c.Series {
+		measured := s
+		if i < len(c.sources) {
+			measured = c.sources[i]
+		}
+		rate, err := measured.sinceFirstValue().CAGR()
+		rankings[i] = &Ranking{Series: s, CAGR: rate, Err: err}
+	}
+	slices.SortStableFunc(rankings, func(a, b *Ranking) int {
+		if (a.Err == nil) != (b.Err == nil) {
+			if a.Err == nil {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.CAGR, a.CAGR)
+	})
+	return rankings
+}
+
+// sinceFirstValue returns the series without its leading zero years. Trailing
+// zero years are kept, so that a collapsing series is not measured as if it
+// were still growing.
+func (s *Series) sinceFirstValue() *Series {
+	points := s.Points
+	for len(points) > 0 && points[0].Value == 0 {
+		points = points[1:]
+	}
+	return &Series{Name: s.Name, Points: points}
+}
@@ -0,0 +1,202 @@
+// Package trends turns yearly counts into dense time series and computes
+// growth statistics over them.
+//
+// Series come from the counts_by_year of authors, sources, institutions,
+// funders, publishers, concepts and works, or from any works query grouped by
+// publication_year. OpenAlex leaves out years without activity, so series
+// fill every year between the first and the last with zero.
+package trends
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// ErrInsufficientData is returned when a statistic is not defined for a
+// series, such as the CAGR of a series that starts at zero.
+var ErrInsufficientData = errors.New("insufficient data")
+
+// Metric selects one of the counts of counts_by_year.
+type Metric int
+
+const (
+	// Works counts the works published in a year.
+	Works Metric = iota
+	// Citations counts the citations received in a year.
+	Citations
+)
+
+// Point is the value of a series in one year.
+type Point struct {
+	Year  int
+	Value float64
+}
+
+// Series is a dense yearly series with one point per year, in order.
+type Series struct {
+	Name   string
+	Points []Point
+}
+
+// New returns the series of the values keyed by year, filling the years
+// between the first and the last that have no value with zero.
+func New(name string, values map[int]float64) *Series {
+	s := &Series{Name: name}
+	if len(values) == 0 {
+		return s
+	}
+	years := slices.Sorted(maps.Keys(values))
+	for year := years[0]; year <= years[len(years)-1]; year++ {
+		s.Points = append(s.Points, Point{Year: year, Value: values[year]})
+	}
+	return s
+}
+
+// FromCounts returns the series of one metric of an entity's counts_by_year,
+// which covers the last ten years.
+func FromCounts(name string, counts []*model.CountsByYear, metric Metric) *Series {
+	values := make(map[int]float64, len(counts))
+	for _, c := range counts {
+		if c == nil || c.Year == 0 {
+			continue
+		}
+		switch metric {
+		case Citations:
+			values[c.Year] += float64(c.CitedByCount)
+		default:
+			values[c.Year] += float64(c.WorksCount)
+		}
+	}
+	return New(name, values)
+}
+
+// FromQuery returns the number of works per publication year of a works
+// query, grouping it by publication_year. Narrow the years with a
+// publication_year filter, and use Range to include years without works at
+// either end. q itself is not changed.
+func FromQuery(ctx context.Context, name string, q *core.QueryBuilder[model.Work]) (*Series, error) {
+	// Grouping a clone leaves q as the caller built it.
+	groups, err := core.ListGroups[int](ctx, q.Clone().GroupBy("publication_year", false))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[int]float64, len(groups.Groups))
+	for _, g := range groups.Groups {
+		if !g.Unknown {
+			values[g.Key] = float64(g.Count)
+		}
+	}
+	return New(name, values), nil
+}
+
+// First returns the first year of the series, or 0 when it is empty.
+func (s *Series) First() int {
+	if len(s.Points) == 0 {
+		return 0
+	}
+	return s.Points[0].Year
+}
+
+// Last returns the last year of the series, or 0 when it is empty.
+func (s *Series) Last() int {
+	if len(s.Points) == 0 {
+		return 0
+	}
+	return s.Points[len(s.Points)-1].Year
+}
+
+// Value returns the value of the series in year, which is zero outside it.
+func (s *Series) Value(year int) float64 {
+	if i := year - s.First(); len(s.Points) > 0 && i >= 0 && i < len(s.Points) {
+		return s.Points[i].Value
+	}
+	return 0
+}
+
+// Range returns the series over the years from from to to inclusive, filling
+// years outside the series with zero. Use it to leave out the current,
+// incomplete year or to align series before comparing them.
+func (s *Series) Range(from, to int) *Series {
+	r := &Series{Name: s.Name}
+	for year := from; year <= to; year++ {
+		r.Points = append(r.Points, Point{Year: year, Value: s.Value(year)})
+	}
+	return r
+}
+
+// Total returns the sum of the values of the series.
+func (s *Series) Total() float64 {
+	var total float64
+	for _, p := range s.Points {
+		total += p.Value
+	}
+	return total
+}
+
+// Cumulative returns the running total of the series.
+func (s *Series) Cumulative() *Series {
+	c := &Series{Name: s.Name, Points: make([]Point, len(s.Points))}
+	var total float64
+	for i, p := range s.Points {
+		total += p.Value
+		c.Points[i] = Point{Year: p.Year, Value: total}
+	}
+	return c
+}
+
+// Growth returns the year-over-year growth rate of the series, starting with
+// its second year. A rate of 0.25 means a 25% increase over the year before.
+// The rate is NaN for years following a year with a value of zero.
+func (s *Series) Growth() *Series {
+	g := &Series{Name: s.Name}
+	for i := 1; i < len(s.Points); i++ {
+		prev, cur := s.Points[i-1].Value, s.Points[i].Value
+		rate := math.NaN()
+		if prev != 0 {
+			rate = (cur - prev) / prev
+		}
+		g.Points = append(g.Points, Point{Year: s.Points[i].Year, Value: rate})
+	}
+	return g
+}
+
+// CAGR returns the compound annual growth rate between the first and the last
+// year of the series. It returns ErrInsufficientData when the series spans
+// less than two years or starts or ends at zero.
+func (s *Series) CAGR() (float64, error) {
+	if len(s.Points) < 2 {
+		return 0, ErrInsufficientData
+	}
+	first, last := s.Points[0].Value, s.Points[len(s.Points)-1].Value
+	if first <= 0 || last <= 0 {
+		return 0, ErrInsufficientData
+	}
+	years := float64(len(s.Points) - 1)
+	return math.Pow(last/first, 1/years) - 1, nil
+}
+
+// MovingAverage returns the trailing moving average of the series over window
+// years, starting with the first year that has a full window.
+func (s *Series) MovingAverage(window int) *Series {
+	m := &Series{Name: s.Name}
+	if window < 1 {
+		return m
+	}
+	var sum float64
+	for i, p := range s.Points {
+		sum += p.Value
+		if i >= window {
+			sum -= s.Points[i-window].Value
+		}
+		if i >= window-1 {
+			m.Points = append(m.Points, Point{Year: p.Year, Value: sum / float64(window)})
+		}
+	}
+	return m
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+const sampleGlobalAutoCompleteResponse = `{
+	"meta": {"count": 4, "db_response_time_ms": 30, "page": 1, "per_page": 10},
+	"results": [
+		{"id": "https://openalex.org/A5023888391", "display_name": "Jason Priem", "entity_type": "author", "filter_key": "authorships.author.id", "works_count": 53},
+		{"id": "https://openalex.org/S4306400194", "display_name": "PeerJ", "entity_type": "source", "filter_key": "primary_location.source.id"},
+		{"id": "https://openalex.org/A5000000001", "display_name": "Jason Prie", "entity_type": "author", "filter_key": "authorships.author.id"},
+		{"id": "https://openalex.org/W2741809807", "display_name": "The state of OA", "entity_type": "work"}
+	]
+}`
+
+func TestGlobalAutoComplete(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.URL.Path != "/autocomplete" || req.URL.Query().Get("q") != "jason pri" {
+			t.Errorf("Unexpected request %s?%s", req.URL.Path, req.URL.RawQuery)
+		}
+		return http.StatusOK, sampleGlobalAutoCompleteResponse
+	}
+	tracer := &recordingTracer{}
+	client := NewTestClient(server.URL, core.WithTracer(tracer))
+
+	groups, err := client.AutoComplete(context.Background(), "jason pri")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups, got %d", len(groups))
+	}
+	want := []string{"author", "source", "work"}
+	for i, g := range groups {
+		if g.EntityType != want[i] {
+			t.Errorf("Expected group %d to be %s, got %s", i, want[i], g.EntityType)
+		}
+	}
+	if len(groups[0].Completions) != 2 || groups[0].Completions[1].DisplayName != "Jason Prie" {
+		t.Errorf("Expected authors in ranked order, got %+v", groups[0].Completions)
+	}
+	if len(tracer.spans) != 1 || tracer.spans[0].name != "AutoComplete" || tracer.spans[0].attrs[core.AttrResultCount] != 4 {
+		t.Errorf("Unexpected spans: %+v", tracer.spans)
+	}
+}
+
+func TestCompletionFilter(t *testing.T) {
+	tests := []struct {
+		comp  *model.Completion
+		field string
+		value string
+	}{
+		{&model.Completion{ID: "https://openalex.org/A5023888391", EntityType: "author", FilterKey: "authorships.author.id"}, "authorships.author.id", "A5023888391"},
+		{&model.Completion{ID: "https://openalex.org/I27837315", EntityType: "institution"}, "authorships.institutions.lineage", "I27837315"},
+		{&model.Completion{ID: "https://openalex.org/W2741809807", EntityType: "work"}, "ids.openalex", "W2741809807"},
+	}
+	for _, tt := range tests {
+		field, value, err := core.CompletionFilter(tt.comp)
+		if err != nil || field != tt.field || value != tt.value {
+			t.Errorf("CompletionFilter(%+v) = %q, %q, %v; want %q, %q", tt.comp, field, value, err, tt.field, tt.value)
+		}
+	}
+	if _, _, err := core.CompletionFilter(&model.Completion{ID: "x", EntityType: "unknown"}); err == nil {
+		t.Error("Expected an error for an entity type without a works filter")
+	}
+	if _, _, err := core.CompletionFilter(&model.Completion{EntityType: "author"}); err == nil {
+		t.Error("Expected an error for a completion without an ID")
+	}
+}
+
+func TestWorksForCompletion(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		// Filters are sent in map order.
+		filters := strings.Split(req.URL.Query().Get("filter"), ",")
+		slices.Sort(filters)
+		if !slices.Equal(filters, []string{"authorships.author.id:A5023888391", "publication_year:2020"}) {
+			t.Errorf("Unexpected filters %v", filters)
+		}
+		return http.StatusOK, SamplePaginatedResponse
+	}
+	client := NewTestClient(server.URL)
+
+	q, err := client.WorksFor(&model.Completion{ID: "https://openalex.org/A5023888391", EntityType: "author", FilterKey: "authorships.author.id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	works, err := q.Filter("publication_year", 2020).List()
+	if err != nil || len(works) != 1 {
+		t.Errorf("Unexpected works: %v, %v", works, err)
+	}
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+func TestAuxiliaryEntityBuilders(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	responses := map[string]string{
+		"/countries": `{"meta": {"count": 1}, "results": [{"id": "https://openalex.org/countries/BR", "display_name": "Brazil",
+			"country_code": "BR", "is_global_south": true, "ids": {"iso": "BR"},
+			"continent": {"id": "https://openalex.org/continents/Q18", "display_name": "South America"}}]}`,
+		"/continents": `{"meta": {"count": 1}, "results": [{"id": "https://openalex.org/continents/Q18", "display_name": "South America",
+			"countries": [{"id": "https://openalex.org/countries/BR", "display_name": "Brazil"}]}]}`,
+		"/sdgs": `{"meta": {"count": 1}, "results": [{"id": "https://metadata.un.org/sdg/3", "display_name": "Good health and well-being",
+			"ids": {"un": "https://metadata.un.org/sdg/3"}, "works_count": 10}]}`,
+		"/languages":         `{"meta": {"count": 1}, "results": [{"id": "https://openalex.org/languages/en", "display_name": "English"}]}`,
+		"/licenses":          `{"meta": {"count": 1}, "results": [{"id": "https://openalex.org/licenses/cc-by", "display_name": "CC BY", "url": "https://creativecommons.org/licenses/by/4.0/"}]}`,
+		"/work-types":        `{"meta": {"count": 1}, "results": [{"id": "https://openalex.org/work-types/article", "display_name": "article", "crossref_types": ["journal-article"]}]}`,
+		"/source-types":      `{"meta": {"count": 1}, "results": [{"id": "https://openalex.org/source-types/journal", "display_name": "journal"}]}`,
+		"/institution-types": `{"meta": {"count": 1}, "results": [{"id": "https://openalex.org/institution-types/education", "display_name": "education"}]}`,
+	}
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.URL.Query().Get("sort") != "works_count:desc" || req.URL.Query().Get("per-page") != "50" {
+			t.Errorf("Expected the shared sort and pagination parameters, got %s", req.URL.RawQuery)
+		}
+		if body, ok := responses[req.URL.Path]; ok {
+			return http.StatusOK, body
+		}
+		t.Errorf("Unexpected path %s", req.URL.Path)
+		return http.StatusNotFound, `{}`
+	}
+	client := NewTestClient(server.URL, core.WithStrictDecoding())
+
+	countries, err := client.Countries().Sort("works_count", true).PerPage(50).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(countries) != 1 || !countries[0].IsGlobalSouth || countries[0].Continent.DisplayName != "South America" || countries[0].IDs.ISO != "BR" {
+		t.Errorf("Unexpected countries: %+v", countries[0])
+	}
+	continents, err := client.Continents().Sort("works_count", true).PerPage(50).List()
+	if err != nil || len(continents[0].Countries) != 1 {
+		t.Errorf("Unexpected continents: %v %v", continents, err)
+	}
+	sdgs, err := client.SDGs().Sort("works_count", true).PerPage(50).List()
+	if err != nil || sdgs[0].IDs.UN == "" || sdgs[0].WorksCount != 10 {
+		t.Errorf("Unexpected SDGs: %v %v", sdgs, err)
+	}
+	languages, err := client.Languages().Sort("works_count", true).PerPage(50).List()
+	if err != nil || languages[0].DisplayName != "English" {
+		t.Errorf("Unexpected languages: %v %v", languages, err)
+	}
+	licenses, err := client.Licenses().Sort("works_count", true).PerPage(50).List()
+	if err != nil || !strings.HasPrefix(licenses[0].URL, "https://creativecommons.org/") {
+		t.Errorf("Unexpected licenses: %v %v", licenses, err)
+	}
+	workTypes, err := client.WorkTypes().Sort("works_count", true).PerPage(50).List()
+	if err != nil || len(workTypes[0].CrossrefTypes) != 1 {
+		t.Errorf("Unexpected work types: %v %v", workTypes, err)
+	}
+	sourceTypes, err := client.SourceTypes().Sort("works_count", true).PerPage(50).List()
+	if err != nil || sourceTypes[0].DisplayName != "journal" {
+		t.Errorf("Unexpected source types: %v %v", sourceTypes, err)
+	}
+	institutionTypes, err := client.InstitutionTypes().Sort("works_count", true).PerPage(50).List()
+	if err != nil || institutionTypes[0].DisplayName != "education" {
+		t.Errorf("Unexpected institution types: %v %v", institutionTypes, err)
+	}
+}
+
+func TestAuxiliaryEntitySpanNames(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusOK, `{"meta": {"count": 0}, "results": []}`)
+	tracer := &recordingTracer{}
+	client := NewTestClient(server.URL, core.WithTracer(tracer))
+
+	if _, err := client.WorkTypes().List(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.InstitutionTypes().Get("education"); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracer.spans) != 2 || tracer.spans[0].name != "WorkTypes.List" || tracer.spans[1].name != "InstitutionTypes.Get" {
+		t.Errorf("Unexpected spans: %+v", tracer.spans)
+	}
+}
@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/bibliometrics"
+	"github.com/Sunhill666/goalex/pkg/bibliometrics/storeutil"
+	"github.com/Sunhill666/goalex/pkg/store"
+)
+
+func indicatorWorks() []*model.Work {
+	return []*model.Work{
+		{ID: "https://openalex.org/W1", PublicationYear: 2018, CitedByCount: 25, FWCI: 2.5,
+			CitationNormalizedPercentile: &model.CitationNormalizedPercentile{Value: 0.99, IsTop1Percent: true, IsTop10Percent: true}},
+		{ID: "https://openalex.org/W2", PublicationYear: 2020, CitedByCount: 12, FWCI: 1.5,
+			CitationNormalizedPercentile: &model.CitationNormalizedPercentile{Value: 0.95, IsTop10Percent: true},
+			CountsByYear:                 []*model.CountsByYear{{Year: 2022, CitedByCount: 5}, {Year: 2021, CitedByCount: 7}}},
+		{ID: "https://openalex.org/W3", PublicationYear: 2021, CitedByCount: 10,
+			CitationNormalizedPercentile: &model.CitationNormalizedPercentile{Value: 0.5},
+			CountsByYear:                 []*model.CountsByYear{{Year: 2022, CitedByCount: 3}}},
+		{ID: "https://openalex.org/W4", PublicationYear: 2021, CitedByCount: 4},
+		{ID: "https://openalex.org/W5", PublicationYear: 2022, CitedByCount: 3},
+		{ID: "https://openalex.org/W6", PublicationYear: 2022},
+		// The same work again, as a short ID.
+		{ID: "W2", PublicationYear: 2020, CitedByCount: 12},
+		nil,
+	}
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestComputeIndicators(t *testing.T) {
+	ind := bibliometrics.Compute(indicatorWorks(), bibliometrics.Options{Year: 2023})
+
+	if ind.Works != 6 || ind.Citations != 54 || !near(ind.MeanCitations, 9) {
+		t.Errorf("Unexpected counts: %+v", ind)
+	}
+	if ind.HIndex != 4 || ind.GIndex != 6 || ind.I10Index != 3 {
+		t.Errorf("Unexpected h, g and i10 indices: %d, %d, %d", ind.HIndex, ind.GIndex, ind.I10Index)
+	}
+	if ind.FirstYear != 2018 || ind.LastYear != 2022 || !near(ind.MQuotient, 4.0/6) {
+		t.Errorf("Unexpected m-quotient: %d-%d, %v", ind.FirstYear, ind.LastYear, ind.MQuotient)
+	}
+	// Works of 2020 and 2021 received 5, 3 and 0 citations in 2022.
+	if !near(ind.TwoYearMeanCitedness, 8.0/3) {
+		t.Errorf("Unexpected 2-year mean citedness: %v", ind.TwoYearMeanCitedness)
+	}
+	if ind.FWCIWorks != 3 || !near(ind.MeanFWCI, 4.0/3) {
+		t.Errorf("Unexpected FWCI: %d works, mean %v", ind.FWCIWorks, ind.MeanFWCI)
+	}
+	if ind.PercentileWorks != 3 || !near(ind.MeanPercentile, 2.44/3) || !near(ind.Top1Share, 1.0/3) || !near(ind.Top10Share, 2.0/3) {
+		t.Errorf("Unexpected percentiles: %+v", ind)
+	}
+}
+
+func TestIndexEdgeCases(t *testing.T) {
+	for _, tc := range []struct {
+		citations []int
+		h, g      int
+	}{
+		{nil, 0, 0},
+		{[]int{0, 0}, 0, 0},
+		{[]int{1}, 1, 1},
+		{[]int{100, 0, 0}, 1, 3},
+		{[]int{3, 3, 3}, 3, 3},
+	} {
+		if h := bibliometrics.HIndex(tc.citations); h != tc.h {
+			t.Errorf("HIndex(%v) = %d, want %d", tc.citations, h, tc.h)
+		}
+		if g := bibliometrics.GIndex(tc.citations); g != tc.g {
+			t.Errorf("GIndex(%v) = %d, want %d", tc.citations, g, tc.g)
+		}
+	}
+	if ind := bibliometrics.Compute(nil, bibliometrics.Options{}); ind.Works != 0 || ind.MeanCitations != 0 || ind.MQuotient != 0 {
+		t.Errorf("Expected empty indicators, got %+v", ind)
+	}
+}
+
+func TestIndicatorsFromQuery(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		q := req.URL.Query()
+		if q.Get("select") != "id,title,publication_year,cited_by_count,counts_by_year,fwci,citation_normalized_percentile" || q.Get("per-page") != "200" {
+			t.Errorf("Unexpected query: %s", req.URL.RawQuery)
+		}
+		if q.Get("cursor") == "*" {
+			return http.StatusOK, `{"meta": {"count": 3, "next_cursor": "next"}, "results": [
+				{"id": "https://openalex.org/W1", "publication_year": 2020, "cited_by_count": 5, "fwci": 2},
+				{"id": "https://openalex.org/W2", "publication_year": 2021, "cited_by_count": 2}]}`
+		}
+		return http.StatusOK, `{"meta": {"count": 3}, "results": [
+			{"id": "https://openalex.org/W3", "publication_year": 2022, "cited_by_count": 2}]}`
+	}
+	client := NewTestClient(server.URL)
+
+	q := client.Works().Filter("publication_year", "2020-2022").Select("id", "title")
+	ind, err := bibliometrics.FromQuery(context.Background(), q, bibliometrics.Options{Year: 2022})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ind.Works != 3 || ind.HIndex != 2 || ind.Citations != 9 || ind.FWCIWorks != 1 || !near(ind.MQuotient, 2.0/3) {
+		t.Errorf("Unexpected indicators: %+v", ind)
+	}
+
+	// The fields and page size are set on a copy.
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if q := req.URL.Query(); q.Get("select") != "id,title" || q.Get("per-page") != "" {
+			t.Errorf("Expected FromQuery to leave the query unchanged, got %s", req.URL.RawQuery)
+		}
+		return http.StatusOK, `{"meta": {"count": 0}, "results": []}`
+	}
+	if _, err := q.List(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndicatorsFromStore(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "mirror.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = s.Close() }()
+	ctx := context.Background()
+	if _, err := s.UpsertWorks(ctx, indicatorWorks()[:6]...); err != nil {
+		t.Fatal(err)
+	}
+
+	ind, err := storeutil.FromStore(ctx, s, store.WorkFilter{FromYear: 2020, ToYear: 2021}, bibliometrics.Options{Year: 2023})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ind.Works != 3 || ind.Citations != 26 || ind.HIndex != 3 || ind.FirstYear != 2020 {
+		t.Errorf("Unexpected indicators: %+v", ind)
+	}
+}
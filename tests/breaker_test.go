@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	var healthy atomic.Bool
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		requests.Add(1)
+		if healthy.Load() {
+			return http.StatusOK, SampleWorkResponse
+		}
+		return http.StatusServiceUnavailable, `{}`
+	}
+
+	var mu sync.Mutex
+	var changes []string
+	breaker := core.NewBreaker(core.BreakerOptions{
+		FailureThreshold: 3,
+		OpenTimeout:      50 * time.Millisecond,
+		OnStateChange: func(from, to core.BreakerState) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})
+	client := NewTestClient(server.URL, core.WithRetry(0, 0), core.WithCircuitBreaker(breaker))
+
+	for range 3 {
+		var apiErr *core.APIError
+		if _, err := client.Works().Get("W1"); !errors.As(err, &apiErr) {
+			t.Fatalf("Expected an APIError while closed, got %v", err)
+		}
+	}
+	if breaker.State() != core.BreakerOpen {
+		t.Fatalf("Expected the breaker to be open, got %s", breaker.State())
+	}
+	_, err := client.Works().Get("W1")
+	var openErr *core.CircuitOpenError
+	if !errors.Is(err, core.ErrCircuitOpen) || !errors.As(err, &openErr) {
+		t.Fatalf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if until := time.Until(openErr.RetryAfter); openErr.State != core.BreakerOpen || until <= 0 || until > 50*time.Millisecond {
+		t.Errorf("Unexpected error: %+v", openErr)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected no request while open, got %d requests", requests.Load())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	healthy.Store(true)
+	if _, err := client.Works().Get("W1"); err != nil {
+		t.Fatalf("Expected the trial request to succeed, got %v", err)
+	}
+	if breaker.State() != core.BreakerClosed {
+		t.Errorf("Expected the breaker to close, got %s", breaker.State())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected transitions %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Expected transitions %v, got %v", want, changes)
+			break
+		}
+	}
+}
+
+func TestCircuitBreakerReopensOnFailedTrial(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusBadGateway, `{}`)
+
+	breaker := core.NewBreaker(core.BreakerOptions{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+	client := NewTestClient(server.URL, core.WithRetry(0, 0), core.WithCircuitBreaker(breaker))
+
+	_, _ = client.Works().List()
+	time.Sleep(30 * time.Millisecond)
+	if breaker.State() != core.BreakerHalfOpen {
+		t.Fatalf("Expected half-open after the timeout, got %s", breaker.State())
+	}
+	_, _ = client.Works().List()
+	if breaker.State() != core.BreakerOpen {
+		t.Errorf("Expected a failed trial to reopen the breaker, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreakerStopsRetries(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		requests.Add(1)
+		return http.StatusInternalServerError, `{}`
+	}
+
+	breaker := core.NewBreaker(core.BreakerOptions{FailureThreshold: 2, OpenTimeout: time.Minute})
+	client := NewTestClient(server.URL, core.WithRetry(5, time.Millisecond), core.WithCircuitBreaker(breaker))
+
+	if _, err := client.Works().List(); !errors.Is(err, core.ErrCircuitOpen) {
+		t.Errorf("Expected retries to end with ErrCircuitOpen, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 requests before the breaker opened, got %d", requests.Load())
+	}
+}
+
+func TestCircuitBreakerIgnoresClientErrors(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusNotFound, `{}`)
+
+	breaker := core.NewBreaker(core.BreakerOptions{FailureThreshold: 1})
+	client := NewTestClient(server.URL, core.WithCircuitBreaker(breaker))
+	for range 3 {
+		_, _ = client.Works().Get("W0")
+	}
+	if breaker.State() != core.BreakerClosed {
+		t.Errorf("Expected 404s to leave the breaker closed, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreakerCountsOnlyTrials(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var slow, trials atomic.Int32
+	releaseSlow := make(chan struct{})
+	releaseTrial := make(chan struct{})
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		switch req.URL.Path {
+		case "/works/W1":
+			slow.Add(1)
+			<-releaseSlow
+		case "/works/W2":
+			return http.StatusBadGateway, `{}`
+		case "/works/W3":
+			trials.Add(1)
+			<-releaseTrial
+		}
+		return http.StatusOK, SampleWorkResponse
+	}
+
+	breaker := core.NewBreaker(core.BreakerOptions{FailureThreshold: 1, OpenTimeout: 30 * time.Millisecond})
+	client := NewTestClient(server.URL, core.WithRetry(0, 0), core.WithCircuitBreaker(breaker))
+	wait := func(n *atomic.Int32, want int32) {
+		for n.Load() < want {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Two attempts let through while closed: one succeeds late, one is cancelled.
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = client.Works().Get("W1")
+	}()
+	go func() {
+		defer wg.Done()
+		_, _ = client.Works().GetWithContext(ctx, "W1")
+	}()
+	wait(&slow, 2)
+
+	_, _ = client.Works().Get("W2")
+	time.Sleep(40 * time.Millisecond)
+	trial := make(chan error, 1)
+	go func() {
+		_, err := client.Works().Get("W3")
+		trial <- err
+	}()
+	wait(&trials, 1)
+
+	cancel()
+	close(releaseSlow)
+	wg.Wait()
+	if breaker.State() != core.BreakerHalfOpen {
+		t.Fatalf("Expected attempts admitted while closed not to settle the trial, got %s", breaker.State())
+	}
+	_, err := client.Works().Get("W4")
+	var openErr *core.CircuitOpenError
+	if !errors.As(err, &openErr) || openErr.State != core.BreakerHalfOpen || !openErr.RetryAfter.IsZero() {
+		t.Errorf("Expected the only trial slot to stay taken, got %v", err)
+	}
+
+	close(releaseTrial)
+	if err := <-trial; err != nil {
+		t.Fatalf("Unexpected trial error: %v", err)
+	}
+	if breaker.State() != core.BreakerClosed {
+		t.Errorf("Expected the trial to close the breaker, got %s", breaker.State())
+	}
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/Sunhill666/goalex/internal/model"
@@ -298,3 +299,32 @@ func TestQueryBuilderGroupBy(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryBuilderClone(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	client := NewTestClient(server.URL)
+
+	var queries []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		q := req.URL.Query()
+		queries = append(queries, q.Get("filter")+" "+q.Get("select")+" "+q.Get("per-page"))
+		return http.StatusOK, SamplePaginatedResponse
+	}
+
+	q := client.Works().Filter("is_oa", true).Select("id").PerPage(10)
+	clone := q.Clone().Filter("type", "article").Select("doi").PerPage(50)
+	if _, err := q.List(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := clone.List(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if queries[0] != "is_oa:true id 10" {
+		t.Errorf("Expected the original query to be unchanged, got %q", queries[0])
+	}
+	if !strings.Contains(queries[1], "type:article") || !strings.HasSuffix(queries[1], " id,doi 50") {
+		t.Errorf("Unexpected cloned query: %q", queries[1])
+	}
+}
@@ -0,0 +1,182 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Sunhill666/goalex/internal/cli"
+)
+
+// runCLI runs the goalex command against the test server.
+func runCLI(server *TestServer, env map[string]string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	getenv := func(key string) string {
+		if key == cli.EnvBaseURL {
+			return server.URL
+		}
+		return env[key]
+	}
+	code := cli.Run(context.Background(), args, &stdout, &stderr, getenv)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLIList(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		q := req.URL.Query()
+		if req.URL.Path != "/works" {
+			t.Errorf("Expected path /works, got %s", req.URL.Path)
+		}
+		if q.Get("filter") != "publication_year:2020" || q.Get("sort") != "cited_by_count:desc" {
+			t.Errorf("Unexpected query: %s", req.URL.RawQuery)
+		}
+		if q.Get("mailto") != "me@example.com" {
+			t.Errorf("Expected mailto from environment, got %q", q.Get("mailto"))
+		}
+		return http.StatusOK, SamplePaginatedResponse
+	}
+
+	code, stdout, stderr := runCLI(server, map[string]string{cli.EnvMailTo: "me@example.com"},
+		"list", "works", "--filter", "publication_year:2020", "--sort", "cited_by_count:desc", "--format", "csv", "--columns", "id,publication_year")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	expected := "id,publication_year\nhttps://openalex.org/W2741809807,2018\n"
+	if stdout != expected {
+		t.Errorf("Unexpected output:\n%s", stdout)
+	}
+}
+
+func TestCLICommands(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		response string
+		path     string
+		contains string
+	}{
+		{"get", []string{"get", "works", "W2741809807"}, SampleWorkResponse, "/works/W2741809807", `"publication_year": 2018`},
+		{"search table", []string{"search", "works", "open access"}, SamplePaginatedResponse, "/works", "The state of OA"},
+		{"count", []string{"count", "works"}, `{"results": [], "meta": {"count": 42}}`, "/works", "42"},
+		{"group", []string{"group", "works", "publication_year"}, `{"results": [], "group_by": [{"key": "2020", "count": 7}], "meta": {"count": 7}}`, "/works", "2020"},
+		{"autocomplete", []string{"autocomplete", "institutions", "harv"}, SampleAutoCompleteResponse, "/autocomplete/institutions", "Harvard University"},
+		{"export jsonl", []string{"export", "works", "--limit", "1"}, SamplePaginatedResponse, "/works", `"doi":"https://doi.org/10.7717/peerj.4375"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.ResponseHandler = func(req *http.Request) (int, string) {
+				if req.URL.Path != tt.path {
+					t.Errorf("Expected path %s, got %s", tt.path, req.URL.Path)
+				}
+				return http.StatusOK, tt.response
+			}
+			code, stdout, stderr := runCLI(server, nil, tt.args...)
+			if code != 0 {
+				t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+			}
+			if !strings.Contains(stdout, tt.contains) {
+				t.Errorf("Expected output to contain %q, got:\n%s", tt.contains, stdout)
+			}
+		})
+	}
+}
+
+func TestCLIErrors(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	for _, args := range [][]string{
+		{},
+		{"frobnicate", "works"},
+		{"list"},
+		{"list", "widgets"},
+		{"get", "works"},
+		{"list", "works", "--filter", "novalue"},
+		{"list", "works", "--format", "xml"},
+	} {
+		if code, _, _ := runCLI(server, nil, args...); code == 0 {
+			t.Errorf("Expected non-zero exit code for %v", args)
+		}
+	}
+}
+
+func TestCLIOutputFile(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "works.csv")
+	if err := os.WriteFile(path, []byte("previous\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	server.SetResponse(http.StatusNotFound, `{"error": "not found"}`)
+	if code, _, _ := runCLI(server, nil, "get", "works", "W1", "--output", path); code == 0 {
+		t.Fatal("Expected a failed request to fail")
+	}
+	if code, _, _ := runCLI(server, nil, "list", "widgets", "--output", path); code == 0 {
+		t.Fatal("Expected an unknown entity to fail")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "previous\n" {
+		t.Errorf("Expected failed commands to leave the file untouched, got %q", data)
+	}
+
+	server.SetResponse(http.StatusOK, SamplePaginatedResponse)
+	if code, _, stderr := runCLI(server, nil, "list", "works", "--format", "csv", "--columns", "id", "--output", path); code != 0 {
+		t.Fatalf("Expected exit code 0, got %d: %s", code, stderr)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "id\nhttps://openalex.org/W2741809807\n" {
+		t.Errorf("Unexpected file contents: %q", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestCLICancel(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		requests.Add(1)
+		return http.StatusOK, SamplePaginatedResponse
+	}
+	getenv := func(key string) string {
+		if key == cli.EnvBaseURL {
+			return server.URL
+		}
+		return ""
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, args := range [][]string{
+		{"get", "works", "W1"},
+		{"list", "works"},
+		{"search", "works", "open access"},
+		{"count", "works"},
+		{"group", "works", "publication_year"},
+		{"autocomplete", "works", "open"},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := cli.Run(ctx, args, &stdout, &stderr, getenv); code == 0 {
+			t.Errorf("Expected %v to fail with a cancelled context", args)
+		}
+	}
+	if requests.Load() != 0 {
+		t.Errorf("Expected no requests with a cancelled context, got %d", requests.Load())
+	}
+}
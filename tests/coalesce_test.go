@@ -0,0 +1,237 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// blockingServer answers every request with SampleWorkResponse once release is closed.
+func blockingServer(t *testing.T) (*TestServer, *atomic.Int32, chan struct{}, chan struct{}) {
+	t.Helper()
+	server := NewTestServer()
+	t.Cleanup(server.Close)
+	var requests atomic.Int32
+	release := make(chan struct{})
+	cancelled := make(chan struct{}, 1)
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		requests.Add(1)
+		select {
+		case <-release:
+			return http.StatusOK, SampleWorkResponse
+		case <-req.Context().Done():
+			cancelled <- struct{}{}
+			return http.StatusServiceUnavailable, `{}`
+		}
+	}
+	return server, &requests, release, cancelled
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRequestCoalescingSharesRoundTrip(t *testing.T) {
+	server, requests, release, _ := blockingServer(t)
+	client := NewTestClient(server.URL, core.WithRequestCoalescing())
+
+	const callers = 10
+	var wg sync.WaitGroup
+	works := make([]string, callers)
+	errs := make([]error, callers)
+	for i := range callers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			work, err := client.Works().GetWithContext(context.Background(), "W2741809807")
+			errs[i] = err
+			if err == nil {
+				works[i] = work.Title
+				work.Title = "modified"
+			}
+		}()
+	}
+	waitFor(t, func() bool { return requests.Load() == 1 })
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", requests.Load())
+	}
+	for i := range callers {
+		if errs[i] != nil {
+			t.Fatalf("Caller %d failed: %v", i, errs[i])
+		}
+		if works[i] == "modified" || works[i] == "" {
+			t.Errorf("Caller %d got a shared or empty result: %q", i, works[i])
+		}
+	}
+
+	// Once the flight has landed, the next call makes a new request.
+	if _, err := client.Works().Get("W2741809807"); err != nil {
+		t.Fatal(err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected a fresh request after completion, got %d requests", requests.Load())
+	}
+}
+
+func TestRequestCoalescingWaiterCancellation(t *testing.T) {
+	server, requests, release, _ := blockingServer(t)
+	client := NewTestClient(server.URL, core.WithRequestCoalescing())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelledErr := make(chan error, 1)
+	go func() {
+		_, err := client.Works().GetWithContext(ctx, "W1")
+		cancelledErr <- err
+	}()
+	waitFor(t, func() bool { return requests.Load() == 1 })
+
+	result := make(chan error, 1)
+	go func() {
+		_, err := client.Works().GetWithContext(context.Background(), "W1")
+		result <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	cancel()
+	if err := <-cancelledErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled waiter to return context.Canceled, got %v", err)
+	}
+	close(release)
+	if err := <-result; err != nil {
+		t.Errorf("Expected the remaining waiter to succeed, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", requests.Load())
+	}
+}
+
+func TestRequestCoalescingCancelsAbandonedRequest(t *testing.T) {
+	server, requests, _, cancelled := blockingServer(t)
+	client := NewTestClient(server.URL, core.WithRequestCoalescing(), core.WithRetry(0, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = client.Works().GetWithContext(ctx, "W1")
+		}()
+	}
+	waitFor(t, func() bool { return requests.Load() == 1 })
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the upstream request to be cancelled once every waiter gave up")
+	}
+}
+
+func TestRequestCoalescingDeadline(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if requests.Add(1) == 1 {
+			return http.StatusServiceUnavailable, `{}`
+		}
+		return http.StatusOK, SampleWorkResponse
+	}
+	var mu sync.Mutex
+	var deadlines []time.Time
+	record := func(next core.Doer) core.Doer {
+		return core.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			deadline, _ := req.Context().Deadline()
+			mu.Lock()
+			deadlines = append(deadlines, deadline)
+			mu.Unlock()
+			return next.Do(req)
+		})
+	}
+	client := NewTestClient(server.URL, core.WithRequestCoalescing(), core.WithRetry(1, 100*time.Millisecond), core.WithMiddleware(record))
+
+	// The first caller gives up during the retry delay; the second one, with a
+	// later deadline, keeps the request going.
+	short, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	first := make(chan error, 1)
+	go func() {
+		_, err := client.Works().GetWithContext(short, "W1")
+		first <- err
+	}()
+	waitFor(t, func() bool { return requests.Load() == 1 })
+	long, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := client.Works().GetWithContext(long, "W1"); err != nil {
+		t.Fatalf("Expected the second caller to succeed, got %v", err)
+	}
+	if err := <-first; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the first caller to time out, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	shortDeadline, _ := short.Deadline()
+	longDeadline, _ := long.Deadline()
+	if len(deadlines) != 2 || !deadlines[0].Equal(shortDeadline) || !deadlines[1].Equal(longDeadline) {
+		t.Errorf("Expected attempts to run until the latest caller deadline, got %v", deadlines)
+	}
+}
+
+func TestRequestCoalescingRetriesWithoutDeadline(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if requests.Add(1) == 1 {
+			// The first attempt outlasts the client's timeout.
+			time.Sleep(150 * time.Millisecond)
+		}
+		return http.StatusOK, SampleWorkResponse
+	}
+	client := NewTestClient(server.URL, core.WithRequestCoalescing(), core.WithTimeout(100*time.Millisecond), core.WithRetry(1, 50*time.Millisecond))
+
+	if _, err := client.Works().GetWithContext(context.Background(), "W1"); err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected 2 attempts, got %d", requests.Load())
+	}
+}
+
+func TestRequestCoalescingDisabledByDefault(t *testing.T) {
+	server, requests, release, _ := blockingServer(t)
+	client := NewTestClient(server.URL)
+
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = client.Works().Get("W1")
+		}()
+	}
+	waitFor(t, func() bool { return requests.Load() == 3 })
+	close(release)
+	wg.Wait()
+}
@@ -0,0 +1,267 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/export"
+)
+
+const sampleExportWork = `{
+	"id": "https://openalex.org/W1",
+	"display_name": "Flat files, deep structs",
+	"publication_year": 2021,
+	"fwci": 1.5,
+	"open_access": {"is_oa": true, "oa_status": "gold"},
+	"primary_location": {"source": {"id": "https://openalex.org/S1", "display_name": "Journal of Tables"}},
+	"authorships": [
+		{"author": {"id": "https://openalex.org/A1", "display_name": "Ada Lovelace"}},
+		{"author": {"id": "https://openalex.org/A2", "display_name": "Charles Babbage"}}
+	]
+}`
+
+func TestExportPathResolve(t *testing.T) {
+	var work model.Work
+	if err := json.Unmarshal([]byte(sampleExportWork), &work); err != nil {
+		t.Fatalf("Failed to unmarshal work: %v", err)
+	}
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"id", []string{"https://openalex.org/W1"}},
+		{"primary_location.source.display_name", []string{"Journal of Tables"}},
+		{"authorships[*].author.display_name", []string{"Ada Lovelace", "Charles Babbage"}},
+		{"authorships[1].author.id", []string{"https://openalex.org/A2"}},
+		{"authorships[5].author.id", nil},
+		{"best_oa_location.source.display_name", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, err := export.ParsePath(tt.path)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			values, err := path.Resolve(&work)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(values) != len(tt.expected) {
+				t.Fatalf("Expected %d values, got %d", len(tt.expected), len(values))
+			}
+			for i, v := range values {
+				if v.String() != tt.expected[i] {
+					t.Errorf("Value %d: expected %s, got %s", i, tt.expected[i], v.String())
+				}
+			}
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		path, _ := export.ParsePath("primary_location.nope")
+		if _, err := path.Resolve(&work); err == nil {
+			t.Error("Expected error for unknown field")
+		}
+	})
+
+	t.Run("invalid path", func(t *testing.T) {
+		for _, raw := range []string{"", "a..b", "authorships[x]", "authorships[1"} {
+			if _, err := export.ParsePath(raw); err == nil {
+				t.Errorf("Expected error for path %q", raw)
+			}
+		}
+	})
+}
+
+func TestExportQuery(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	client := NewTestClient(server.URL)
+
+	pages := 0
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		pages++
+		if got := req.URL.Query().Get("select"); got != "id,authorships,open_access" {
+			t.Errorf("Expected select=id,authorships,open_access, got %s", got)
+		}
+		if req.URL.Query().Get("cursor") == "*" {
+			return http.StatusOK, `{"results": [` + sampleExportWork + `], "meta": {"count": 2, "next_cursor": "page2"}}`
+		}
+		return http.StatusOK, `{"results": [` + sampleExportWork + `], "meta": {"count": 2}}`
+	}
+
+	columns, err := export.ParseColumns("id", "authors=authorships[*].author.display_name", "open_access.oa_status")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		pages = 0
+		var buf bytes.Buffer
+		n, err := export.Query(context.Background(), client.Works(), &buf, export.Options{
+			Format:  export.FormatCSV,
+			Columns: columns,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if n != 2 || pages != 2 {
+			t.Errorf("Expected 2 records over 2 pages, got %d records over %d pages", n, pages)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if lines[0] != "id,authors,open_access.oa_status" {
+			t.Errorf("Unexpected header: %s", lines[0])
+		}
+		if lines[1] != "https://openalex.org/W1,Ada Lovelace; Charles Babbage,gold" {
+			t.Errorf("Unexpected row: %s", lines[1])
+		}
+	})
+
+	t.Run("jsonl with limit", func(t *testing.T) {
+		pages = 0
+		var buf bytes.Buffer
+		n, err := export.Query(context.Background(), client.Works(), &buf, export.Options{
+			Format:  export.FormatJSONL,
+			Columns: columns,
+			Limit:   1,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if n != 1 || pages != 1 {
+			t.Errorf("Expected 1 record from 1 page, got %d records over %d pages", n, pages)
+		}
+		var row map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &row); err != nil {
+			t.Fatalf("Invalid JSON line: %v", err)
+		}
+		if authors, ok := row["authors"].([]any); !ok || len(authors) != 2 {
+			t.Errorf("Expected authors to be a list of 2, got %v", row["authors"])
+		}
+		if row["id"] != "https://openalex.org/W1" {
+			t.Errorf("Unexpected id: %v", row["id"])
+		}
+	})
+
+	t.Run("csv without columns", func(t *testing.T) {
+		_, err := export.Query(context.Background(), client.Works(), &bytes.Buffer{}, export.Options{Format: export.FormatCSV})
+		if err == nil {
+			t.Error("Expected error for CSV export without columns")
+		}
+	})
+}
+
+func TestExportQueryKeepsBuilder(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	client := NewTestClient(server.URL)
+
+	var queries []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		queries = append(queries, req.URL.Query().Get("select")+" "+req.URL.Query().Get("per-page"))
+		return http.StatusOK, `{"results": [` + sampleExportWork + `], "meta": {"count": 1}}`
+	}
+
+	columns, err := export.ParseColumns("id")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	q := client.Works()
+	if _, err := export.Query(context.Background(), q, io.Discard, export.Options{Format: export.FormatCSV, Columns: columns}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := q.List(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(queries) != 2 || queries[0] != "id 200" || queries[1] != " " {
+		t.Errorf("Expected the export to leave the query unchanged, got %q", queries)
+	}
+}
+
+func TestExportQueryEmptyAndSelected(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	client := NewTestClient(server.URL)
+
+	var selects []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		selects = append(selects, req.URL.Query().Get("select"))
+		return http.StatusOK, `{"results": [], "meta": {"count": 0}}`
+	}
+
+	columns, err := export.ParseColumns("id", "title", "Year=publication_year")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	q := client.Works().Select("id", "doi")
+	n, err := export.Query(context.Background(), q, &buf, export.Options{Format: export.FormatCSV, Columns: columns})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != 0 || buf.String() != "id,title,Year\n" {
+		t.Errorf("Expected only the header for an empty result, got %d records and %q", n, buf.String())
+	}
+	if len(selects) != 1 || selects[0] != "id,doi,title,publication_year" {
+		t.Errorf("Expected each field to be selected once, got %q", selects)
+	}
+}
+
+func TestExportQueryTable(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	client := NewTestClient(server.URL)
+
+	var perPages []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		q := req.URL.Query()
+		perPages = append(perPages, q.Get("per-page"))
+		switch q.Get("cursor") {
+		case "*":
+			return http.StatusOK, `{"results": [{"id": "W1", "display_name": "Short"}], "meta": {"next_cursor": "page2"}}`
+		case "page2":
+			return http.StatusOK, `{"results": [{"id": "W22222222", "display_name": "Longer"}], "meta": {"next_cursor": "page3"}}`
+		default:
+			return http.StatusOK, `{"results": [], "meta": {}}`
+		}
+	}
+
+	columns, err := export.ParseColumns("id", "display_name")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := export.Query(context.Background(), client.Works(), &buf, export.Options{Format: export.FormatTable, Columns: columns}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected a header and two rows, got:\n%s", buf.String())
+	}
+	column := strings.Index(lines[0], "display_name")
+	for _, line := range lines[1:] {
+		if strings.Index(line, "Short")+strings.Index(line, "Longer")+1 != column {
+			t.Errorf("Expected rows of every page to be aligned, got:\n%s", buf.String())
+		}
+	}
+
+	perPages = nil
+	if _, err := export.Query(context.Background(), client.Works(), io.Discard, export.Options{Format: export.FormatTable, Columns: columns, Limit: 1}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(perPages) != 1 || perPages[0] != "1" {
+		t.Errorf("Expected a single page of one record, got per-page %q", perPages)
+	}
+}
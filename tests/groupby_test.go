@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestListGroupBys(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if got := req.URL.Query().Get("group_bys"); got != "publication_year,type:include_unknown" {
+			t.Errorf("Unexpected group_bys %q", got)
+		}
+		return http.StatusOK, `{
+			"meta": {"count": 120, "db_response_time_ms": 40},
+			"group_bys": [
+				{"group_by_key": "publication_year", "groups": [{"key": "2020", "key_display_name": "2020", "count": 70}, {"key": "2021", "key_display_name": "2021", "count": 50}]},
+				{"group_by_key": "type", "groups": [{"key": "https://openalex.org/work-types/article", "key_display_name": "article", "count": 100}, {"key": "unknown", "key_display_name": "unknown", "count": 20}]}
+			]
+		}`
+	}
+	client := NewTestClient(server.URL)
+
+	groups, meta, err := client.Works().GroupBys("publication_year", "type:include_unknown").ListGroupBysWithContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta == nil || meta.Count != 120 {
+		t.Errorf("Unexpected meta: %+v", meta)
+	}
+	if len(groups) != 2 || len(groups["publication_year"]) != 2 || groups["publication_year"][0].Count != 70 {
+		t.Errorf("Unexpected publication_year groups: %+v", groups["publication_year"])
+	}
+	if types := groups["type"]; len(types) != 2 || types[1].Key != "unknown" {
+		t.Errorf("Unexpected type groups: %+v", types)
+	}
+}
+
+func TestAllGroups(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	pages := map[string]struct {
+		keys []string
+		next string
+	}{
+		"*":  {[]string{"A1", "A2"}, "c2"},
+		"c2": {[]string{"A3", "A4"}, "c3"},
+		"c3": {[]string{"A5"}, ""},
+	}
+	var cursors []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		q := req.URL.Query()
+		if q.Get("group_by") != "authorships.author.id" || q.Get("per-page") != "200" {
+			t.Errorf("Unexpected query: %s", req.URL.RawQuery)
+		}
+		cursor := q.Get("cursor")
+		cursors = append(cursors, cursor)
+		page := pages[cursor]
+		var groups []string
+		for _, k := range page.keys {
+			groups = append(groups, fmt.Sprintf(`{"key": "https://openalex.org/%s", "key_display_name": "Author %s", "count": 1}`, k, k))
+		}
+		next := "null"
+		if page.next != "" {
+			next = `"` + page.next + `"`
+		}
+		return http.StatusOK, fmt.Sprintf(`{"meta": {"count": 5, "next_cursor": %s}, "group_by": [%s]}`, next, strings.Join(groups, ","))
+	}
+	client := NewTestClient(server.URL)
+
+	q := client.Works().Filter("publication_year", 2020).GroupBy("authorships.author.id", false)
+	var keys []string
+	for g, err := range q.AllGroups(context.Background()) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, strings.TrimPrefix(g.Key, "https://openalex.org/"))
+	}
+	if strings.Join(keys, ",") != "A1,A2,A3,A4,A5" {
+		t.Errorf("Expected every group across pages, got %v", keys)
+	}
+	if strings.Join(cursors, ",") != "*,c2,c3" {
+		t.Errorf("Unexpected cursors: %v", cursors)
+	}
+
+	cursors = nil
+	for range q.AllGroups(context.Background()) {
+		break
+	}
+	if len(cursors) != 1 {
+		t.Errorf("Expected iteration to stop after the first page, got cursors %v", cursors)
+	}
+
+	// The builder keeps its own pagination.
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if q := req.URL.Query(); q.Get("per-page") != "" || q.Get("cursor") != "" {
+			t.Errorf("Expected AllGroups to leave the builder unchanged, got %s", req.URL.RawQuery)
+		}
+		return http.StatusOK, `{"meta": {"count": 0}, "group_by": []}`
+	}
+	if _, err := q.ListGroupBy(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAllGroupsError(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusBadRequest, `{}`)
+	client := NewTestClient(server.URL)
+
+	for g, err := range client.Works().GroupBy("type", false).AllGroups(context.Background()) {
+		if err == nil || g != nil {
+			t.Errorf("Expected a single error, got %v, %v", g, err)
+		}
+	}
+
+	var errs []error
+	for _, err := range client.Works().AllGroups(context.Background()) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || errs[0] == nil || !strings.Contains(errs[0].Error(), "no group-by field") {
+		t.Errorf("Expected an error for a query without a group-by field, got %v", errs)
+	}
+}
@@ -0,0 +1,251 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+func TestListGroupsParsesKeys(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		switch req.URL.Query().Get("group_by") {
+		case "publication_year":
+			return http.StatusOK, `{"meta": {"count": 200}, "group_by": [
+				{"key": "2021", "key_display_name": "2021", "count": 120},
+				{"key": "2020", "key_display_name": "2020", "count": 80}]}`
+		case "open_access.is_oa:include_unknown":
+			return http.StatusOK, `{"meta": {"count": 200}, "group_by": [
+				{"key": "true", "key_display_name": "true", "count": 150},
+				{"key": "false", "key_display_name": "false", "count": 40},
+				{"key": "unknown", "key_display_name": "unknown", "count": 10}]}`
+		default:
+			return http.StatusOK, `{"meta": {"count": 200}, "group_by": [
+				{"key": "https://openalex.org/work-types/article", "key_display_name": "article", "count": 180},
+				{"key": "https://openalex.org/S1", "key_display_name": "Nature", "count": 20}]}`
+		}
+	}
+	client := NewTestClient(server.URL)
+	ctx := context.Background()
+
+	years, err := core.ListGroups[int](ctx, client.Works().GroupBy("publication_year", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if years.Field != "publication_year" || years.Total != 200 || fmt.Sprint(years.Keys()) != "[2021 2020]" {
+		t.Errorf("Unexpected year groups: %+v", years)
+	}
+	if g, ok := years.Get(2020); !ok || g.Share != 0.4 || g.Percent() != 40 {
+		t.Errorf("Unexpected 2020 group: %+v", g)
+	}
+
+	oa, err := core.ListGroups[bool](ctx, client.Works().GroupBy("open_access.is_oa", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, ok := oa.Get(true); !ok || g.Count != 150 || g.Share != 0.75 {
+		t.Errorf("Unexpected open access group: %+v", g)
+	}
+	if g, ok := oa.Unknown(); !ok || g.Key || g.Count != 10 {
+		t.Errorf("Unexpected unknown group: %+v", g)
+	}
+	if len(oa.Keys()) != 2 {
+		t.Errorf("Expected the unknown group to be left out of the keys, got %v", oa.Keys())
+	}
+
+	ids, err := core.ListGroups[string](ctx, client.Works().GroupBy("type", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids.Keys()) != "[article S1]" || ids.Groups[1].RawKey != "https://openalex.org/S1" {
+		t.Errorf("Unexpected string keys: %v", ids.Keys())
+	}
+
+	if _, err := core.ListGroups[int](ctx, client.Works().GroupBy("type", false)); err == nil || !strings.Contains(err.Error(), "type") {
+		t.Errorf("Expected a parse error naming the field, got %v", err)
+	}
+	if _, err := core.ListGroups[int](ctx, client.Works()); err == nil {
+		t.Error("Expected an error for a query without a group-by")
+	}
+}
+
+func TestParseGroupsWithoutTotal(t *testing.T) {
+	groups, err := core.ParseGroups[int]("publication_year", []*model.GroupBy{{Key: "2020", Count: 3}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if groups.Total != 0 || groups.Groups[0].Share != 0 {
+		t.Errorf("Expected no shares without meta, got %+v", groups.Groups[0])
+	}
+}
+
+func TestHydrateGroups(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		q := req.URL.Query()
+		requests = append(requests, req.URL.Path+" "+q.Get("filter")+" "+q.Get("select"))
+		ids := strings.Split(strings.TrimPrefix(q.Get("filter"), "openalex:"), "|")
+		var results []string
+		for _, id := range ids {
+			if id == "A404" {
+				continue
+			}
+			results = append(results, fmt.Sprintf(`{"id": "https://openalex.org/%s", "display_name": "Author %s", "orcid": "https://orcid.org/%s"}`, id, id, id))
+		}
+		return http.StatusOK, `{"meta": {"count": 2}, "results": [` + strings.Join(results, ",") + `]}`
+	}
+	client := NewTestClient(server.URL)
+
+	groups, err := core.ParseGroups[string]("authorships.author.id", []*model.GroupBy{
+		{Key: "https://openalex.org/A1", Count: 5},
+		{Key: "https://openalex.org/A404", Count: 3},
+		{Key: "unknown", Count: 2},
+		{Key: "https://openalex.org/A2", Count: 1},
+	}, &model.PaginatedResponseMeta{Count: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hydrated, err := core.HydrateGroups[model.DehydratedAuthor](context.Background(), client, groups, core.BatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 1 || requests[0] != "/authors openalex:A1|A404|A2 display_name,id,orcid" {
+		t.Errorf("Expected one batched request selecting the model fields, got %v", requests)
+	}
+	if len(hydrated) != 4 || hydrated[0].Entity == nil || hydrated[0].Entity.DisplayName != "Author A1" ||
+		hydrated[3].Entity == nil || hydrated[3].Entity.ORCID != "https://orcid.org/A2" {
+		t.Errorf("Unexpected hydrated entities: %+v", hydrated)
+	}
+	if hydrated[1].Entity != nil || hydrated[2].Entity != nil || hydrated[0].Share != 0.5 {
+		t.Errorf("Expected missing and unknown entities to stay nil: %+v %+v", hydrated[1], hydrated[2])
+	}
+}
+
+func TestHydrateGroupsChunksAndErrors(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var sizes []int
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		ids := strings.Split(strings.TrimPrefix(req.URL.Query().Get("filter"), "openalex:"), "|")
+		sizes = append(sizes, len(ids))
+		if len(ids) < 100 {
+			return http.StatusBadRequest, `{}`
+		}
+		return http.StatusOK, `{"meta": {"count": 0}, "results": []}`
+	}
+	client := NewTestClient(server.URL)
+
+	var raw []*model.GroupBy
+	for i := range 150 {
+		raw = append(raw, &model.GroupBy{Key: fmt.Sprintf("https://openalex.org/I%d", i+1), Count: 1})
+	}
+	groups, _ := core.ParseGroups[string]("authorships.institutions.id", raw, nil)
+	hydrated, err := core.HydrateGroups[model.DehydratedInstitution](context.Background(), client, groups, core.BatchOptions{Concurrency: 1})
+	if err == nil {
+		t.Error("Expected the failed chunk to be reported")
+	}
+	if len(hydrated) != 150 || fmt.Sprint(sizes) != "[100 50]" {
+		t.Errorf("Expected chunks of 100 IDs, got %v", sizes)
+	}
+}
+
+func TestHydrateGroupsTypedIDs(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		requests = append(requests, req.URL.Path+" "+req.URL.Query().Get("select"))
+		switch req.URL.Path {
+		case "/keywords/machine-learning":
+			return http.StatusOK, `{"id": "https://openalex.org/keywords/machine-learning", "display_name": "Machine Learning"}`
+		case "/subfields/1702":
+			return http.StatusOK, `{"id": "https://openalex.org/subfields/1702", "display_name": "Artificial Intelligence"}`
+		case "/fields/17":
+			return http.StatusOK, `{"id": "https://openalex.org/fields/17", "display_name": "Computer Science"}`
+		}
+		t.Errorf("Unexpected request %s", req.URL)
+		return http.StatusNotFound, `{}`
+	}
+	client := NewTestClient(server.URL)
+
+	groups, err := core.ParseGroups[string]("mixed", []*model.GroupBy{
+		{Key: "https://openalex.org/keywords/machine-learning", Count: 3},
+		{Key: "https://openalex.org/subfields/1702", Count: 2},
+		{Key: "https://openalex.org/fields/17", Count: 1},
+		{Key: "2020", Count: 1},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The type has no id field, but entities are still keyed by their id.
+	type named struct {
+		DisplayName string `json:"display_name"`
+	}
+	hydrated, err := core.HydrateGroups[named](context.Background(), client, groups, core.BatchOptions{Concurrency: 1})
+	if err == nil || !strings.Contains(err.Error(), `"2020"`) {
+		t.Errorf("Expected an error for the key that is not an entity ID, got %v", err)
+	}
+	want := []string{"Machine Learning", "Artificial Intelligence", "Computer Science"}
+	for i, name := range want {
+		if hydrated[i].Entity == nil || hydrated[i].Entity.DisplayName != name {
+			t.Errorf("Expected %s for %s, got %+v", name, hydrated[i].RawKey, hydrated[i].Entity)
+		}
+	}
+	if hydrated[3].Entity != nil {
+		t.Errorf("Expected no entity for a plain key, got %+v", hydrated[3].Entity)
+	}
+	if len(requests) != 3 || requests[0] != "/keywords/machine-learning display_name,id" {
+		t.Errorf("Expected one request per typed ID selecting id, got %v", requests)
+	}
+}
+
+func TestHydrateGroupsSelectsRoots(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var selects []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		selects = append(selects, req.URL.Query().Get("select"))
+		return http.StatusOK, `{"meta": {"count": 1}, "results": [{"id": "https://openalex.org/A1", "display_name": "Author A1",
+			"summary_stats": {"h_index": 12}, "last_known_institutions": [{"id": "https://openalex.org/I1"}]}]}`
+	}
+	client := NewTestClient(server.URL)
+	groups, err := core.ParseGroups[string]("authorships.author.id", []*model.GroupBy{{Key: "https://openalex.org/A1", Count: 1}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authors, err := core.HydrateGroups[model.Author](context.Background(), client, groups, core.BatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selects[0] != "" {
+		t.Errorf("Expected a full model to select every field, got %q", selects[0])
+	}
+	if a := authors[0].Entity; a == nil || a.DisplayName != "Author A1" || a.SummaryStats == nil || len(a.LastKnownInstitutions) != 1 {
+		t.Errorf("Unexpected author: %+v", a)
+	}
+
+	// Embedded structs contribute their members and nested structs only their root.
+	type summary struct {
+		model.DehydratedAuthor
+		Stats *model.SummaryStats `json:"summary_stats"`
+	}
+	summaries, err := core.HydrateGroups[summary](context.Background(), client, groups, core.BatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selects[1] != "display_name,id,orcid,summary_stats" {
+		t.Errorf("Expected only root fields to be selected, got %q", selects[1])
+	}
+	if s := summaries[0].Entity; s == nil || s.DisplayName != "Author A1" || s.Stats == nil {
+		t.Errorf("Unexpected summary: %+v", s)
+	}
+}
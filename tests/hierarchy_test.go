@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+const (
+	sampleDomainsResponse = `{"meta": {"count": 2}, "results": [
+		{"id": "https://openalex.org/domains/3", "display_name": "Physical Sciences", "works_count": 5,
+		 "display_name_alternatives": ["physical science"], "ids": {"wikidata": "https://www.wikidata.org/wiki/Q7754"},
+		 "fields": [{"id": "https://openalex.org/fields/17", "display_name": "Computer Science"}],
+		 "siblings": [{"id": "https://openalex.org/domains/1", "display_name": "Life Sciences"}]},
+		{"id": "https://openalex.org/domains/1", "display_name": "Life Sciences"}
+	]}`
+	sampleFieldsResponse = `{"meta": {"count": 2}, "results": [
+		{"id": "https://openalex.org/fields/17", "display_name": "Computer Science",
+		 "domain": {"id": "https://openalex.org/domains/3", "display_name": "Physical Sciences"},
+		 "subfields": [{"id": "https://openalex.org/subfields/1702", "display_name": "Artificial Intelligence"}]},
+		{"id": "https://openalex.org/fields/99", "display_name": "Orphan",
+		 "domain": {"id": "https://openalex.org/domains/9", "display_name": "Missing"}}
+	]}`
+	sampleSubfieldsResponse = `{"meta": {"count": 1}, "results": [
+		{"id": "https://openalex.org/subfields/1702", "display_name": "Artificial Intelligence",
+		 "field": {"id": "https://openalex.org/fields/17", "display_name": "Computer Science"},
+		 "domain": {"id": "https://openalex.org/domains/3", "display_name": "Physical Sciences"},
+		 "topics": [{"id": "https://openalex.org/T10028", "display_name": "Topic Modeling"}]}
+	]}`
+	sampleTopicsResponse = `{"meta": {"count": 2}, "results": [
+		{"id": "https://openalex.org/T10028", "display_name": "Topic Modeling",
+		 "subfield": {"id": "https://openalex.org/subfields/1702", "display_name": "Artificial Intelligence"}},
+		{"id": "https://openalex.org/T10181", "display_name": "Natural Language Processing Techniques",
+		 "subfield": {"id": "https://openalex.org/subfields/1702", "display_name": "Artificial Intelligence"}}
+	]}`
+)
+
+func hierarchyServer(t *testing.T) *TestServer {
+	t.Helper()
+	server := NewTestServer()
+	t.Cleanup(server.Close)
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		switch req.URL.Path {
+		case "/domains":
+			return http.StatusOK, sampleDomainsResponse
+		case "/fields":
+			return http.StatusOK, sampleFieldsResponse
+		case "/subfields":
+			return http.StatusOK, sampleSubfieldsResponse
+		case "/topics":
+			return http.StatusOK, sampleTopicsResponse
+		case "/domains/3":
+			return http.StatusOK, `{"id": "https://openalex.org/domains/3", "display_name": "Physical Sciences", "description": "branch of natural science"}`
+		}
+		t.Errorf("Unexpected path %s", req.URL.Path)
+		return http.StatusNotFound, `{}`
+	}
+	return server
+}
+
+func TestHierarchyBuilders(t *testing.T) {
+	server := hierarchyServer(t)
+	client := NewTestClient(server.URL, core.WithStrictDecoding())
+
+	domains, err := client.Domains().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 2 || domains[0].DisplayName != "Physical Sciences" || domains[0].WorksCount != 5 {
+		t.Fatalf("Unexpected domains: %+v", domains)
+	}
+	if domains[0].IDs == nil || domains[0].IDs.Wikidata == "" || len(domains[0].Fields) != 1 || len(domains[0].Siblings) != 1 {
+		t.Errorf("Expected ids, fields and siblings on the domain, got %+v", domains[0])
+	}
+
+	domain, err := client.Domains().Get("3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if domain.Description != "branch of natural science" {
+		t.Errorf("Unexpected domain: %+v", domain)
+	}
+
+	fields, err := client.Fields().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields[0].Domain == nil || fields[0].Domain.ID != "https://openalex.org/domains/3" || len(fields[0].Subfields) != 1 {
+		t.Errorf("Unexpected field: %+v", fields[0])
+	}
+
+	subfields, err := client.Subfields().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subfields[0].Field == nil || len(subfields[0].Topics) != 1 {
+		t.Errorf("Unexpected subfield: %+v", subfields[0])
+	}
+}
+
+func TestLoadTopicTree(t *testing.T) {
+	server := hierarchyServer(t)
+	client := NewTestClient(server.URL)
+
+	tree, err := core.LoadTopicTree(context.Background(), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree.Domains) != 2 || tree.Domains[0].DisplayName != "Life Sciences" {
+		t.Fatalf("Expected domains sorted by name, got %d domains", len(tree.Domains))
+	}
+	if tree.Len() != 2 {
+		t.Errorf("Expected 2 topics, got %d", tree.Len())
+	}
+
+	topic := tree.Topic("t10028")
+	if topic == nil {
+		t.Fatal("Expected to find topic T10028")
+	}
+	if topic.Parent != tree.Subfield("1702") || topic.Parent.Parent != tree.Field("https://openalex.org/fields/17") {
+		t.Error("Expected the topic to link to its subfield and field")
+	}
+	if tree.Domain("3").Fields[0].Subfields[0].Topics[1] != topic {
+		t.Error("Expected topics sorted by name under their subfield")
+	}
+	want := []string{"Physical Sciences", "Computer Science", "Artificial Intelligence", "Topic Modeling"}
+	if got := tree.Path("https://openalex.org/T10028"); !slices.Equal(got, want) {
+		t.Errorf("Expected path %v, got %v", want, got)
+	}
+	if tree.Field("99") != nil {
+		t.Error("Expected a field without a known domain to be left out")
+	}
+	if tree.Path("T1") != nil {
+		t.Error("Expected no path for an unknown topic")
+	}
+}
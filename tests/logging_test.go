@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+// logRecords decodes JSON log lines into maps.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+		var r map[string]any
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestLoggerRecordsAttempts(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if requests.Add(1) == 1 {
+			return http.StatusServiceUnavailable, `{}`
+		}
+		return http.StatusOK, SamplePaginatedResponse
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewTestClient(server.URL, core.WithLogger(logger), core.Auth("secret-key"), core.PolitePool("me@example.com"))
+
+	if _, err := client.Works().Filter("publication_year", 2020).List(); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "secret-key") || strings.Contains(buf.String(), "me@example.com") {
+		t.Errorf("Expected credentials to be redacted:\n%s", buf.String())
+	}
+
+	records := logRecords(t, &buf)
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 log records, got %d:\n%s", len(records), buf.String())
+	}
+
+	query, retry, success := records[0], records[1], records[2]
+	if query["level"] != "DEBUG" || query["msg"] != "openalex query" || query["query"] != "filter=publication_year:2020" {
+		t.Errorf("Unexpected query record: %v", query)
+	}
+	if retry["level"] != "WARN" || retry["retry_reason"] != "status 503" || retry["status"] != float64(503) || retry["attempt"] != float64(1) {
+		t.Errorf("Unexpected retry record: %v", retry)
+	}
+	if !strings.Contains(retry["url"].(string), "api_key=REDACTED") || retry["method"] != "GET" {
+		t.Errorf("Expected redacted URL and method, got %v", retry)
+	}
+	if success["level"] != "DEBUG" || success["status"] != float64(200) || success["attempt"] != float64(2) {
+		t.Errorf("Unexpected success record: %v", success)
+	}
+	if size, _ := success["size"].(float64); size == 0 {
+		t.Errorf("Expected a response size, got %v", success["size"])
+	}
+	if _, ok := success["duration"]; !ok {
+		t.Errorf("Expected a duration, got %v", success)
+	}
+}
+
+func TestLoggerRecordsFinalFailure(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusNotFound, `{}`)
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	client := NewTestClient(server.URL, core.WithLogger(logger))
+
+	if _, err := client.Works().Get("W0"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	records := logRecords(t, &buf)
+	if len(records) != 1 || records[0]["level"] != "ERROR" || records[0]["status"] != float64(404) {
+		t.Errorf("Expected a single error record, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,381 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Sunhill666/goalex/pkg/mcp"
+)
+
+// rpcResponse is a decoded JSON-RPC response from the MCP server.
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// toolCallResult is the result of a tools/call request.
+type toolCallResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StructuredContent map[string]any `json:"structuredContent"`
+	IsError           bool           `json:"isError"`
+}
+
+func rpc(t *testing.T, s *mcp.Server, method string, params any) *rpcResponse {
+	t.Helper()
+	p, _ := json.Marshal(params)
+	msg := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":%q,"params":%s}`, method, p)
+	var resp rpcResponse
+	if err := json.Unmarshal(s.Handle(context.Background(), []byte(msg)), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return &resp
+}
+
+func callTool(t *testing.T, s *mcp.Server, name string, args any) *toolCallResult {
+	t.Helper()
+	resp := rpc(t, s, "tools/call", map[string]any{"name": name, "arguments": args})
+	if resp.Error != nil {
+		t.Fatalf("Unexpected protocol error: %s", resp.Error.Message)
+	}
+	var result toolCallResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("Failed to decode tool result: %v", err)
+	}
+	return &result
+}
+
+func TestMCPInitializeAndListTools(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	s := mcp.NewServer(NewTestClient(server.URL), mcp.Options{})
+
+	resp := rpc(t, s, "initialize", map[string]any{"protocolVersion": "2025-03-26"})
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	_ = json.Unmarshal(resp.Result, &init)
+	if init.ProtocolVersion != "2025-03-26" || init.ServerInfo.Name != "goalex" {
+		t.Errorf("Unexpected initialize result: %s", resp.Result)
+	}
+
+	if out := s.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); out != nil {
+		t.Errorf("Expected no response to a notification, got %s", out)
+	}
+
+	resp = rpc(t, s, "tools/list", nil)
+	var list struct {
+		Tools []struct {
+			Name        string `json:"name"`
+			InputSchema struct {
+				Type       string                     `json:"type"`
+				Properties map[string]json.RawMessage `json:"properties"`
+				Required   []string                   `json:"required"`
+			} `json:"inputSchema"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(resp.Result, &list); err != nil {
+		t.Fatalf("Failed to decode tools: %v", err)
+	}
+	names := []string{}
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+		if tool.InputSchema.Type != "object" {
+			t.Errorf("Expected object schema for %s", tool.Name)
+		}
+		if tool.Name == "search_works" {
+			for _, p := range []string{"query", "filter", "sort", "select", "per_page", "sample"} {
+				if _, ok := tool.InputSchema.Properties[p]; !ok {
+					t.Errorf("Expected search_works schema to have %s", p)
+				}
+			}
+		}
+		if tool.Name == "get_entity" && (len(tool.InputSchema.Required) != 1 || tool.InputSchema.Required[0] != "id") {
+			t.Errorf("Expected get_entity to require id, got %v", tool.InputSchema.Required)
+		}
+	}
+	if got := strings.Join(names, ","); got != "search_works,get_entity,group_by,autocomplete,citation_neighbors" {
+		t.Errorf("Unexpected tools: %s", got)
+	}
+
+	if resp := rpc(t, s, "resources/list", nil); resp.Error == nil || resp.Error.Code != -32601 {
+		t.Errorf("Expected method not found, got %+v", resp)
+	}
+	if resp := rpc(t, s, "tools/call", map[string]any{"name": "nope"}); resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected invalid params for unknown tool, got %+v", resp)
+	}
+}
+
+func TestMCPSearchWorks(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		q := req.URL.Query()
+		if q.Get("search") != "open access" || q.Get("filter") != "publication_year:2018" || q.Get("per-page") != "5" {
+			t.Errorf("Unexpected query: %s", req.URL.RawQuery)
+		}
+		return http.StatusOK, SamplePaginatedResponse
+	}
+	s := mcp.NewServer(NewTestClient(server.URL), mcp.Options{MaxResults: 5})
+
+	result := callTool(t, s, "search_works", map[string]any{
+		"query":    "open access",
+		"filter":   map[string]string{"publication_year": "2018"},
+		"per_page": 50,
+	})
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %s", result.Content[0].Text)
+	}
+	if result.StructuredContent["count"] != float64(1) {
+		t.Errorf("Expected count 1, got %v", result.StructuredContent["count"])
+	}
+	if !strings.Contains(result.Content[0].Text, "W2741809807") {
+		t.Errorf("Expected result text to contain the work, got %s", result.Content[0].Text)
+	}
+
+	result = callTool(t, s, "search_works", map[string]any{"bogus": true})
+	if !result.IsError {
+		t.Error("Expected unknown argument to be reported as a tool error")
+	}
+}
+
+func TestMCPGetEntityByExternalID(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	s := mcp.NewServer(NewTestClient(server.URL), mcp.Options{})
+
+	tests := []struct {
+		id   string
+		path string
+	}{
+		{"https://openalex.org/w2741809807", "/works/W2741809807"},
+		{"https://doi.org/10.7717/peerj.4375", "/works/doi:10.7717/peerj.4375"},
+		{"10.7717/peerj.4375", "/works/doi:10.7717/peerj.4375"},
+		{"0000-0002-3100-3734", "/authors/orcid:0000-0002-3100-3734"},
+		{"https://ror.org/03vek6s52", "/institutions/ror:03vek6s52"},
+		{"1234-567X", "/sources/issn:1234-567X"},
+		{"pmid:29456894", "/works/pmid:29456894"},
+	}
+	for _, tt := range tests {
+		server.ResponseHandler = func(req *http.Request) (int, string) {
+			if req.URL.Path != tt.path {
+				t.Errorf("%s: expected path %s, got %s", tt.id, tt.path, req.URL.Path)
+			}
+			return http.StatusOK, SampleWorkResponse
+		}
+		result := callTool(t, s, "get_entity", map[string]any{"id": tt.id})
+		if result.IsError {
+			t.Errorf("%s: unexpected error %s", tt.id, result.Content[0].Text)
+			continue
+		}
+		if result.StructuredContent["canonical_id"] != "https://openalex.org/W2741809807" {
+			t.Errorf("%s: unexpected canonical ID %v", tt.id, result.StructuredContent["canonical_id"])
+		}
+	}
+
+	if result := callTool(t, s, "get_entity", map[string]any{"id": "not an id"}); !result.IsError {
+		t.Error("Expected unrecognized identifier to fail")
+	}
+	if result := callTool(t, s, "get_entity", map[string]any{}); !result.IsError {
+		t.Error("Expected missing id to fail")
+	}
+}
+
+func TestMCPGroupByAndAutocomplete(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if strings.HasPrefix(req.URL.Path, "/autocomplete/institutions") {
+			return http.StatusOK, SampleAutoCompleteResponse
+		}
+		if req.URL.Query().Get("group_by") != "oa_status" {
+			t.Errorf("Unexpected group_by: %s", req.URL.RawQuery)
+		}
+		return http.StatusOK, `{"results": [], "meta": {"count": 10}, "group_by": [
+			{"key": "gold", "count": 6}, {"key": "closed", "count": 3}, {"key": "green", "count": 1}]}`
+	}
+	s := mcp.NewServer(NewTestClient(server.URL), mcp.Options{MaxResults: 2})
+
+	result := callTool(t, s, "group_by", map[string]any{"field": "oa_status"})
+	if groups, _ := result.StructuredContent["results"].([]any); len(groups) != 2 || result.StructuredContent["truncated"] != true {
+		t.Errorf("Expected 2 of 3 groups and truncated, got %v", result.StructuredContent)
+	}
+
+	// Groups are trimmed to fit the byte limit like any other results.
+	small := mcp.NewServer(NewTestClient(server.URL), mcp.Options{MaxResults: 3, MaxBytes: 100})
+	result = callTool(t, small, "group_by", map[string]any{"field": "oa_status"})
+	groups, _ := result.StructuredContent["results"].([]any)
+	if len(groups) == 0 || len(groups) == 3 || result.StructuredContent["truncated"] != true {
+		t.Errorf("Expected groups to be truncated to the byte limit, got %v", result.StructuredContent)
+	}
+	if len(result.Content[0].Text) > 100 {
+		t.Errorf("Expected at most 100 bytes, got %d: %s", len(result.Content[0].Text), result.Content[0].Text)
+	}
+
+	result = callTool(t, s, "autocomplete", map[string]any{"query": "harv", "entity": "institutions"})
+	if !strings.Contains(result.Content[0].Text, "Harvard University") {
+		t.Errorf("Unexpected autocomplete result: %s", result.Content[0].Text)
+	}
+	if result := callTool(t, s, "autocomplete", map[string]any{"query": "harv", "entity": "widgets"}); !result.IsError {
+		t.Error("Expected invalid entity to fail")
+	}
+}
+
+func TestMCPCitationNeighbors(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.URL.Path == "/works/W1" {
+			return http.StatusOK, `{"id": "https://openalex.org/W1", "display_name": "Root", "referenced_works": ["https://openalex.org/W2", "https://openalex.org/W3"]}`
+		}
+		filter := req.URL.Query().Get("filter")
+		switch {
+		case filter == "openalex:W2|W3":
+			return http.StatusOK, `{"results": [{"id": "https://openalex.org/W2"}, {"id": "https://openalex.org/W3"}], "meta": {"count": 2}}`
+		case filter == "cites:W1":
+			if req.URL.Query().Get("sort") != "cited_by_count:desc" {
+				t.Errorf("Expected citing works sorted by citations, got %s", req.URL.RawQuery)
+			}
+			return http.StatusOK, `{"results": [{"id": "https://openalex.org/W9"}], "meta": {"count": 40}}`
+		}
+		t.Errorf("Unexpected request: %s", req.URL)
+		return http.StatusNotFound, `{}`
+	}
+	s := mcp.NewServer(NewTestClient(server.URL), mcp.Options{})
+
+	result := callTool(t, s, "citation_neighbors", map[string]any{"id": "W1"})
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %s", result.Content[0].Text)
+	}
+	refs := result.StructuredContent["references"].(map[string]any)
+	citing := result.StructuredContent["cited_by"].(map[string]any)
+	if refs["count"] != float64(2) || len(refs["results"].([]any)) != 2 {
+		t.Errorf("Unexpected references: %v", refs)
+	}
+	if citing["count"] != float64(40) || len(citing["results"].([]any)) != 1 {
+		t.Errorf("Unexpected citing works: %v", citing)
+	}
+}
+
+func TestMCPTruncation(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var results []string
+	for i := range 10 {
+		results = append(results, fmt.Sprintf(`{"id": "https://openalex.org/W%d", "display_name": "Work %d", "abstract_inverted_index": {"%s": [0]}}`, i, i, strings.Repeat("x", 500)))
+	}
+	server.SetResponse(http.StatusOK, `{"results": [`+strings.Join(results, ",")+`], "meta": {"count": 1000}}`)
+
+	s := mcp.NewServer(NewTestClient(server.URL), mcp.Options{MaxBytes: 400})
+	result := callTool(t, s, "search_works", map[string]any{})
+	text := result.Content[0].Text
+	if len(text) > 400 {
+		t.Errorf("Expected result under 400 bytes, got %d", len(text))
+	}
+	if result.StructuredContent["truncated"] != true {
+		t.Error("Expected result to be marked truncated")
+	}
+	if strings.Contains(text, "xxxx") || !strings.Contains(text, "abstract_inverted_index") {
+		t.Errorf("Expected the abstract to be dropped and reported, got %s", text)
+	}
+	if !strings.Contains(text, "W0") {
+		t.Errorf("Expected leading results to be kept, got %s", text)
+	}
+}
+
+func TestMCPTruncationStaysWithinLimit(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var results []string
+	for i := range 200 {
+		results = append(results, fmt.Sprintf(`{"id": "https://openalex.org/W%d", "display_name": "Work %d", "title": "Title %d", "cited_by_count": %d}`, i, i, i, i))
+	}
+	server.SetResponse(http.StatusOK, `{"results": [`+strings.Join(results, ",")+`], "meta": {"count": 1000}}`)
+
+	for _, limit := range []int{100, 120, 250, 1000, 5000} {
+		s := mcp.NewServer(NewTestClient(server.URL), mcp.Options{MaxBytes: limit})
+		result := callTool(t, s, "search_works", map[string]any{})
+		if text := result.Content[0].Text; len(text) > limit {
+			t.Errorf("Expected result under %d bytes including the markers, got %d: %s", limit, len(text), text)
+		}
+		if result.StructuredContent["truncated"] != true {
+			t.Errorf("Expected result under %d bytes to be marked truncated", limit)
+		}
+	}
+}
+
+func TestMCPRateLimit(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	s := mcp.NewServer(NewTestClient(server.URL), mcp.Options{RateLimit: 0.001, Burst: 1})
+
+	if result := callTool(t, s, "search_works", map[string]any{}); result.IsError {
+		t.Fatalf("Unexpected tool error: %s", result.Content[0].Text)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out := s.Handle(ctx, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"search_works","arguments":{}}}`))
+	if !bytes.Contains(out, []byte(`"isError":true`)) {
+		t.Errorf("Expected a rate-limited call to fail once its context is done, got %s", out)
+	}
+}
+
+func TestMCPTransports(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	s := mcp.NewServer(NewTestClient(server.URL), mcp.Options{})
+
+	in := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}` + "\n" +
+		`not json` + "\n")
+	var out bytes.Buffer
+	if err := s.ServeStdio(context.Background(), in, &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(out.String(), `"result":{}`) || !strings.Contains(out.String(), `-32700`) {
+		t.Errorf("Unexpected stdio output: %s", out.String())
+	}
+
+	httpServer := httptest.NewServer(s)
+	defer httpServer.Close()
+	resp, err := http.Post(httpServer.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":"a","method":"ping"}`))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Unexpected HTTP response: %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	resp, err = http.Post(httpServer.URL, "application/json", strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected 202 for a notification, got %d", resp.StatusCode)
+	}
+	resp, err = http.Get(httpServer.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+	"github.com/Sunhill666/goalex/pkg/snapshot"
+)
+
+func TestShortID(t *testing.T) {
+	tests := map[string]string{
+		"https://openalex.org/A123": "A123",
+		"https://openalex.org/w42":  "W42",
+		"A123":                      "A123",
+		"doi:10.1/abc":              "doi:10.1/abc",
+		"https://orcid.org/0000":    "https://orcid.org/0000",
+	}
+	for in, expected := range tests {
+		if got := core.ShortID(in); got != expected {
+			t.Errorf("ShortID(%q): expected %q, got %q", in, expected, got)
+		}
+	}
+}
+
+func TestResolveRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/authors/A1":
+			http.Redirect(w, r, "/authors/A2", http.StatusMovedPermanently)
+		case "/authors/A2":
+			_, _ = w.Write([]byte(`{"id": "https://openalex.org/A2", "display_name": "Survivor"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewTestClient(server.URL)
+
+	t.Run("merged id", func(t *testing.T) {
+		author, res, err := client.Authors().Resolve(context.Background(), "A1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if author.DisplayName != "Survivor" {
+			t.Errorf("Unexpected author: %s", author.DisplayName)
+		}
+		if !res.Redirected || res.RequestedID != "A1" || res.CanonicalID != "https://openalex.org/A2" {
+			t.Errorf("Unexpected resolution: %+v", res)
+		}
+	})
+
+	t.Run("canonical id", func(t *testing.T) {
+		_, res, err := client.Authors().Resolve(context.Background(), "A2")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if res.Redirected {
+			t.Errorf("Expected no redirect, got %+v", res)
+		}
+	})
+
+	t.Run("get still follows redirects", func(t *testing.T) {
+		author, err := client.Authors().Get("A1")
+		if err != nil || author.ID != "https://openalex.org/A2" {
+			t.Errorf("Unexpected result: %+v (%v)", author, err)
+		}
+	})
+
+	t.Run("get reports merges to the handler", func(t *testing.T) {
+		var merges []core.Resolution
+		client := NewTestClient(server.URL, core.WithMergeHandler(func(res core.Resolution) {
+			merges = append(merges, res)
+		}))
+		author, err := client.Authors().Get("A1")
+		if err != nil || author.DisplayName != "Survivor" {
+			t.Fatalf("Unexpected result: %+v (%v)", author, err)
+		}
+		if _, err := client.Authors().GetWithContext(context.Background(), "A2"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(merges) != 1 || merges[0].RequestedID != "A1" || merges[0].CanonicalID != "https://openalex.org/A2" {
+			t.Errorf("Expected one merge from A1 to A2, got %+v", merges)
+		}
+	})
+}
+
+func TestMergedIDs(t *testing.T) {
+	m := snapshot.NewMergedIDs()
+	err := m.Load(strings.NewReader("merge_date,id,merge_into_id\n2024-01-01,A1,A2\n2024-02-01,A2,A3\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got, ok := m.Resolve("A1"); !ok || got != "A3" {
+		t.Errorf("Expected A1 to resolve to A3, got %s", got)
+	}
+	if got, ok := m.Resolve("https://openalex.org/A2"); !ok || got != "https://openalex.org/A3" {
+		t.Errorf("Expected URL form to be kept, got %s", got)
+	}
+	if got, ok := m.Resolve("A3"); ok || got != "A3" {
+		t.Errorf("Expected A3 to be canonical, got %s", got)
+	}
+	if date, _ := m.MergeDate("A2"); date != "2024-02-01" || !m.IsMerged("A1") || m.IsMerged("A3") {
+		t.Errorf("Unexpected merge metadata")
+	}
+
+	t.Run("from snapshot", func(t *testing.T) {
+		root := t.TempDir()
+		dir := filepath.Join(root, "data", "merged_ids", "authors")
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = gz.Write([]byte("merge_date,id,merge_into_id\n2024-01-01,A10,A11\n"))
+		_ = gz.Close()
+		if err := os.WriteFile(filepath.Join(dir, "2024-01-01.csv.gz"), buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		snap, _ := snapshot.Open(root)
+		merged, err := snap.MergedIDs(snapshot.EntityAuthors)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got, _ := merged.Resolve("A10"); got != "A11" || merged.Len() != 1 {
+			t.Errorf("Expected A10 to resolve to A11, got %s", got)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+func TestMiddlewareSeesEveryAttempt(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.Header.Get("X-Team") != "triage" {
+			t.Errorf("Expected middleware header, got %q", req.Header.Get("X-Team"))
+		}
+		if requests.Add(1) == 1 {
+			return http.StatusServiceUnavailable, `{}`
+		}
+		return http.StatusOK, SampleWorkResponse
+	}
+
+	var attempts []int
+	var errs []error
+	var statuses []int
+	audit := func(next core.Doer) core.Doer {
+		return core.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("X-Team", "triage")
+			resp, err := next.Do(req)
+			attempts = append(attempts, core.AttemptFromContext(req.Context()))
+			errs = append(errs, err)
+			if resp != nil {
+				statuses = append(statuses, resp.StatusCode)
+			}
+			return resp, err
+		})
+	}
+
+	client := NewTestClient(server.URL, core.WithMiddleware(audit))
+	work, err := client.Works().Get("W2741809807")
+	if err != nil || work.ID != "https://openalex.org/W2741809807" {
+		t.Fatalf("Unexpected result: %v %v", work, err)
+	}
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("Expected attempts [1 2], got %v", attempts)
+	}
+	var apiErr *core.APIError
+	if !errors.As(errs[0], &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the first attempt to see a 503 APIError, got %v", errs[0])
+	}
+	if errs[1] != nil || statuses[1] != http.StatusOK {
+		t.Errorf("Expected the second attempt to succeed, got %v %v", errs[1], statuses)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	var calls []string
+	named := func(name string) core.Middleware {
+		return func(next core.Doer) core.Doer {
+			return core.DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				resp, err := next.Do(req)
+				calls = append(calls, name+" after")
+				return resp, err
+			})
+		}
+	}
+
+	client := NewTestClient(server.URL, core.WithMiddleware(named("a")), core.WithMiddleware(named("b")))
+	if _, err := client.Works().List(); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if got := strings.Join(calls, ", "); got != "a before, b before, b after, a after" {
+		t.Errorf("Unexpected order: %s", got)
+	}
+}
+
+func TestMiddlewareInjectsFailures(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		requests.Add(1)
+		return http.StatusOK, SamplePaginatedResponse
+	}
+
+	chaos := func(next core.Doer) core.Doer {
+		return core.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if core.AttemptFromContext(req.Context()) == 1 {
+				return &http.Response{
+					StatusCode: http.StatusBadGateway,
+					Status:     "502 Bad Gateway",
+					Body:       io.NopCloser(strings.NewReader("")),
+					Request:    req,
+				}, nil
+			}
+			return next.Do(req)
+		})
+	}
+
+	client := NewTestClient(server.URL, core.WithMiddleware(chaos))
+	resp, err := core.ListEntities[model.Work](client, core.EndpointWorks, nil)
+	if err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if len(resp.Results) != 1 || requests.Load() != 1 {
+		t.Errorf("Expected 1 result from 1 upstream request, got %d results and %d requests", len(resp.Results), requests.Load())
+	}
+
+	client = NewTestClient(server.URL, core.WithRetry(0, 0), core.WithMiddleware(chaos))
+	_, err = client.Works().List()
+	var apiErr *core.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway {
+		t.Errorf("Expected injected 502 without retries, got %v", err)
+	}
+}
+
+func TestMiddlewareSeesDecodeError(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusOK, `{not json`)
+
+	var seen error
+	client := NewTestClient(server.URL, core.WithMiddleware(func(next core.Doer) core.Doer {
+		return core.DoerFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.Do(req)
+			seen = err
+			return resp, err
+		})
+	}))
+	if _, err := client.Works().List(); err == nil {
+		t.Fatal("Expected a decode error")
+	}
+	if seen == nil || !strings.Contains(seen.Error(), "failed to decode response") {
+		t.Errorf("Expected middleware to see the decode error, got %v", seen)
+	}
+}
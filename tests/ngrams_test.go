@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+const sampleNGramsResponse = `{
+	"meta": {"count": 2, "doi": "https://doi.org/10.1104/pp.113.4.1089", "openalex_id": "https://openalex.org/W2023271753"},
+	"ngrams": [
+		{"ngram": "plant", "ngram_tokens": 1, "ngram_count": 32, "term_frequency": 0.0054},
+		{"ngram": "cell wall", "ngram_tokens": 2, "ngram_count": 7, "term_frequency": 0.0012}
+	]
+}`
+
+func TestNGrams(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.URL.Path != "/works/W2023271753/ngrams" {
+			t.Errorf("Unexpected path %s", req.URL.Path)
+		}
+		return http.StatusOK, sampleNGramsResponse
+	}
+	client := NewTestClient(server.URL)
+
+	ngrams, err := client.Works().NGrams(context.Background(), "https://openalex.org/W2023271753")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ngrams) != 2 {
+		t.Fatalf("Expected 2 n-grams, got %d", len(ngrams))
+	}
+	if g := ngrams[1]; g.Text != "cell wall" || g.Tokens != 2 || g.Count != 7 || g.TermFrequency != 0.0012 {
+		t.Errorf("Unexpected n-gram: %+v", g)
+	}
+}
+
+func TestNGramsEmptyAndUnavailable(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	client := NewTestClient(server.URL, core.WithRetry(0, 0))
+
+	server.SetResponse(http.StatusOK, `{"meta": {"count": 0}, "ngrams": []}`)
+	ngrams, err := client.Works().NGrams(context.Background(), "W1")
+	if err != nil || ngrams == nil || len(ngrams) != 0 {
+		t.Errorf("Expected an empty, non-nil result, got %v, %v", ngrams, err)
+	}
+
+	for _, status := range []int{http.StatusNotFound, http.StatusServiceUnavailable} {
+		server.SetResponse(status, `{}`)
+		_, err := client.Works().NGrams(context.Background(), "W1")
+		if !errors.Is(err, core.ErrNGramsUnavailable) {
+			t.Errorf("Expected ErrNGramsUnavailable for status %d, got %v", status, err)
+		}
+		var apiErr *core.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != status {
+			t.Errorf("Expected the APIError to remain available, got %v", err)
+		}
+	}
+
+	server.SetResponse(http.StatusForbidden, `{}`)
+	if _, err := client.Works().NGrams(context.Background(), "W1"); err == nil || errors.Is(err, core.ErrNGramsUnavailable) {
+		t.Errorf("Expected other errors to pass through, got %v", err)
+	}
+
+	if _, err := client.Authors().NGrams(context.Background(), "A1"); err == nil {
+		t.Error("Expected an error for n-grams of a non-work entity")
+	}
+}
+
+func TestNGramsBatch(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var inFlight, peak atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if strings.Contains(req.URL.Path, "W404") {
+			return http.StatusNotFound, `{}`
+		}
+		return http.StatusOK, sampleNGramsResponse
+	}
+	client := NewTestClient(server.URL, core.WithRetry(0, 0), core.WithRateLimit(0, 0))
+
+	ids := []string{"W1", "W404", "W3", "W4", "W5", "W6"}
+	results := client.Works().NGramsBatch(context.Background(), ids, core.BatchOptions{Concurrency: 2})
+	if len(results) != len(ids) {
+		t.Fatalf("Expected %d results, got %d", len(ids), len(results))
+	}
+	for i, r := range results {
+		if r.ID != ids[i] {
+			t.Errorf("Expected results in input order, got %s at %d", r.ID, i)
+		}
+		if ids[i] == "W404" {
+			if !errors.Is(r.Err, core.ErrNGramsUnavailable) {
+				t.Errorf("Expected W404 to be unavailable, got %v", r.Err)
+			}
+			continue
+		}
+		if r.Err != nil || len(r.NGrams) != 2 {
+			t.Errorf("Unexpected result for %s: %+v", r.ID, r)
+		}
+	}
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", peak.Load())
+	}
+}
+
+func TestNGramsBatchRateLimitAndCancel(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusOK, sampleNGramsResponse)
+	client := NewTestClient(server.URL, core.WithRateLimit(20, 1))
+
+	// Concurrent batches share the client's limit.
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, ids := range [][]string{{"W1", "W2"}, {"W3", "W4"}} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, r := range client.Works().NGramsBatch(context.Background(), ids, core.BatchOptions{Concurrency: 2}) {
+				if r.Err != nil {
+					t.Errorf("Unexpected error for %s: %v", r.ID, r.Err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 140*time.Millisecond {
+		t.Errorf("Expected the rate limit to space requests across batches, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := client.Works().NGramsBatch(ctx, []string{"W1", "W2"}, core.BatchOptions{})
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("Expected context.Canceled for %s, got %v", r.ID, r.Err)
+		}
+	}
+}
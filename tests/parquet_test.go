@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/export"
+)
+
+func TestParquetSchema(t *testing.T) {
+	schema, err := export.ParquetSchema[model.Work]()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, path := range [][]string{
+		{"id"},
+		{"primary_location", "source", "display_name"},
+		{"authorships", "list", "element", "author", "display_name"},
+		{"abstract_inverted_index", "key_value", "value", "list", "element"},
+	} {
+		if _, ok := schema.Lookup(path...); !ok {
+			t.Errorf("Expected column %v in schema", path)
+		}
+	}
+
+	if _, err := export.ParquetSchema[string](); err == nil {
+		t.Error("Expected error for non-struct type")
+	}
+}
+
+func TestParquetWriter(t *testing.T) {
+	var work model.Work
+	if err := json.Unmarshal([]byte(sampleExportWork), &work); err != nil {
+		t.Fatalf("Failed to unmarshal work: %v", err)
+	}
+	work.AbstractInvertedIndex = map[string][]int{"flat": {0, 3}, "files": {1}}
+
+	var buf bytes.Buffer
+	w, err := export.NewParquetWriter[model.Work](&buf, export.ParquetOptions{Compression: "zstd", RowGroupSize: 2})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	empty := model.Work{ID: "https://openalex.org/W2"}
+	if err := w.Write(&work, &empty, &work); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	file, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Failed to open parquet file: %v", err)
+	}
+	if file.NumRows() != 3 {
+		t.Errorf("Expected 3 rows, got %d", file.NumRows())
+	}
+	if n := len(file.RowGroups()); n != 2 {
+		t.Errorf("Expected 2 row groups, got %d", n)
+	}
+
+	type authorRow struct {
+		Author *struct {
+			DisplayName *string `parquet:"display_name,optional"`
+		} `parquet:"author,optional"`
+	}
+	type row struct {
+		ID          *string      `parquet:"id,optional"`
+		FWCI        *float32     `parquet:"fwci,optional"`
+		Authorships []*authorRow `parquet:"authorships,optional,list"`
+	}
+	rows := make([]row, 3)
+	reader := parquet.NewGenericReader[row](bytes.NewReader(buf.Bytes()))
+	defer func() { _ = reader.Close() }()
+	if n, _ := reader.Read(rows); n != 3 {
+		t.Fatalf("Expected to read 3 rows, got %d", n)
+	}
+
+	if rows[0].ID == nil || *rows[0].ID != "https://openalex.org/W1" {
+		t.Errorf("Unexpected id: %v", rows[0].ID)
+	}
+	if rows[0].FWCI == nil || *rows[0].FWCI != 1.5 {
+		t.Errorf("Unexpected fwci: %v", rows[0].FWCI)
+	}
+	if len(rows[0].Authorships) != 2 || *rows[0].Authorships[1].Author.DisplayName != "Charles Babbage" {
+		t.Errorf("Unexpected authorships: %+v", rows[0].Authorships)
+	}
+	if len(rows[1].Authorships) != 0 {
+		t.Errorf("Expected no authorships for the empty work, got %+v", rows[1].Authorships)
+	}
+}
+
+func TestQueryParquet(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	client := NewTestClient(server.URL)
+	var perPage []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		perPage = append(perPage, req.URL.Query().Get("per-page"))
+		return http.StatusOK, SamplePaginatedResponse
+	}
+
+	var buf bytes.Buffer
+	q := client.Works()
+	n, err := export.QueryParquet(context.Background(), q, &buf, export.ParquetOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("Expected 1 record, got %d", n)
+	}
+	if _, err := q.List(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(perPage) != 2 || perPage[0] != "200" || perPage[1] != "" {
+		t.Errorf("Expected the export to leave the query unchanged, got per-page %q", perPage)
+	}
+	if _, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Errorf("Invalid parquet file: %v", err)
+	}
+
+	if _, err := export.QueryParquet(context.Background(), client.Works(), &buf, export.ParquetOptions{Compression: "lzo"}); err == nil {
+		t.Error("Expected error for unsupported compression")
+	}
+}
@@ -0,0 +1,274 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+	"github.com/Sunhill666/goalex/pkg/proxy"
+)
+
+func proxyGet(t *testing.T, ctx context.Context, url, caller string) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if caller != "" {
+		req.Header.Set(proxy.DefaultCallerHeader, caller)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+// proxyClientOptions configures a proxy's upstream client like NewTestClient.
+func proxyClientOptions(baseURL string, opts ...core.Option) []core.Option {
+	return append([]core.Option{core.WithBaseURL(baseURL), core.WithTimeout(time.Second), core.WithRetry(1, 100*time.Millisecond)}, opts...)
+}
+
+func TestProxyCachesResponses(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var upstream atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		upstream.Add(1)
+		q := req.URL.Query()
+		if q.Get("mailto") != "proxy@example.com" || q.Get("api_key") != "secret" {
+			t.Errorf("Expected the proxy's credentials upstream, got %s", req.URL.RawQuery)
+		}
+		return http.StatusOK, SampleWorkResponse
+	}
+
+	p := proxy.New(proxy.Options{}, proxyClientOptions(server.URL, core.PolitePool("proxy@example.com"), core.Auth("secret"))...)
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	resp, body := proxyGet(t, context.Background(), front.URL+"/works/W2741809807?mailto=alice@example.com", "")
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Cache") != proxy.CacheMiss {
+		t.Fatalf("Unexpected first response: %d %s", resp.StatusCode, resp.Header.Get("X-Cache"))
+	}
+	var work struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(body), &work); err != nil || work.ID != "https://openalex.org/W2741809807" {
+		t.Errorf("Unexpected body: %s", body)
+	}
+
+	resp, _ = proxyGet(t, context.Background(), front.URL+"/works/W2741809807?mailto=alice@example.com", "")
+	if resp.Header.Get("X-Cache") != proxy.CacheHit {
+		t.Errorf("Expected a cache hit, got %s", resp.Header.Get("X-Cache"))
+	}
+	if upstream.Load() != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", upstream.Load())
+	}
+
+	m := p.Metrics()
+	alice := m.Callers["alice@example.com"]
+	if alice.Requests != 2 || alice.CacheHits != 1 || alice.Upstream != 1 || alice.Bytes == 0 {
+		t.Errorf("Unexpected usage for alice: %+v", alice)
+	}
+	if m.CachedItems != 1 {
+		t.Errorf("Expected 1 cached item, got %d", m.CachedItems)
+	}
+}
+
+func TestProxyCoalescesConcurrentRequests(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var upstream atomic.Int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if upstream.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		return http.StatusOK, SamplePaginatedResponse
+	}
+
+	p := proxy.New(proxy.Options{}, proxyClientOptions(server.URL)...)
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	var wg sync.WaitGroup
+	for i := range 5 {
+		if i == 1 {
+			<-started
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, _ := proxyGet(t, context.Background(), front.URL+"/works?filter=publication_year:2020", "team")
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Unexpected status %d", resp.StatusCode)
+			}
+		}()
+	}
+	for p.Metrics().Total.Requests < 5 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if upstream.Load() != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", upstream.Load())
+	}
+	team := p.Metrics().Callers["team"]
+	if team.Upstream != 1 || team.Coalesced+team.CacheHits != 4 {
+		t.Errorf("Unexpected usage: %+v", team)
+	}
+}
+
+func TestProxyErrors(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var upstream atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		upstream.Add(1)
+		return http.StatusNotFound, `{"error": "Not Found"}`
+	}
+
+	p := proxy.New(proxy.Options{}, proxyClientOptions(server.URL)...)
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	for range 2 {
+		resp, _ := proxyGet(t, context.Background(), front.URL+"/works/W0", "bob")
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected upstream 404 to pass through, got %d", resp.StatusCode)
+		}
+	}
+	if upstream.Load() != 2 {
+		t.Errorf("Expected errors not to be cached, got %d upstream requests", upstream.Load())
+	}
+	if errs := p.Metrics().Callers["bob"].Errors; errs != 2 {
+		t.Errorf("Expected 2 errors, got %d", errs)
+	}
+
+	resp, err := http.Post(front.URL+"/works", "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", resp.StatusCode)
+	}
+
+	resp, body := proxyGet(t, context.Background(), front.URL+proxy.MetricsPath, "")
+	var m proxy.Metrics
+	if err := json.Unmarshal([]byte(body), &m); err != nil || resp.StatusCode != http.StatusOK || m.Total.Errors != 2 {
+		t.Errorf("Unexpected metrics response: %s", body)
+	}
+}
+
+func TestProxyRateLimit(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	shared := &http.Client{Timeout: time.Second}
+	p := proxy.New(proxy.Options{RateLimit: 0.001, Burst: 1}, proxyClientOptions(server.URL, core.WithHTTPClient(shared))...)
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	if resp, _ := proxyGet(t, context.Background(), front.URL+"/works?page=1", ""); resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected first request to pass, got %d", resp.StatusCode)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, front.URL+"/works?page=2", nil)
+	if resp, err := http.DefaultClient.Do(req); err == nil {
+		_ = resp.Body.Close()
+		t.Errorf("Expected second request to wait for the limiter, got %d", resp.StatusCode)
+	}
+
+	if shared.Transport != nil {
+		t.Errorf("Expected the limiter to leave the HTTP client passed in unchanged")
+	}
+}
+
+func TestProxyRateLimitUnderLoad(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var upstream atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		upstream.Add(1)
+		return http.StatusOK, SampleWorkResponse
+	}
+
+	// 20 requests at 40 per second queue for up to half a second, well past
+	// the upstream client's timeout of 100ms.
+	const n = 20
+	p := proxy.New(proxy.Options{RateLimit: 40, Burst: 1}, proxyClientOptions(server.URL, core.WithTimeout(100*time.Millisecond))...)
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	var wg sync.WaitGroup
+	statuses := make([]int, n)
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, _ := proxyGet(t, context.Background(), front.URL+"/works?page="+strconv.Itoa(i+1), "")
+			statuses[i] = resp.StatusCode
+		}()
+	}
+	wg.Wait()
+
+	for i, status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("Expected queued request %d to succeed, got %d", i+1, status)
+		}
+	}
+	if upstream.Load() != n {
+		t.Errorf("Expected %d upstream requests without retries, got %d", n, upstream.Load())
+	}
+}
+
+func TestProxyCacheBytes(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var upstream atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		upstream.Add(1)
+		if req.URL.Path == "/works/W_LARGE" {
+			return http.StatusOK, `{"id": "W_LARGE", "abstract": "` + strings.Repeat("x", 200) + `"}`
+		}
+		return http.StatusOK, `{"id": "` + strings.TrimPrefix(req.URL.Path, "/works/") + `"}`
+	}
+
+	p := proxy.New(proxy.Options{CacheBytes: 40, EntryBytes: 100}, proxyClientOptions(server.URL)...)
+	front := httptest.NewServer(p)
+	defer front.Close()
+
+	for range 2 {
+		if resp, _ := proxyGet(t, context.Background(), front.URL+"/works/W_LARGE", ""); resp.Header.Get("X-Cache") != proxy.CacheMiss {
+			t.Errorf("Expected bodies above EntryBytes not to be cached, got %s", resp.Header.Get("X-Cache"))
+		}
+	}
+
+	// Three of the small bodies fit in CacheBytes.
+	for _, id := range []string{"W1", "W2", "W3", "W4"} {
+		proxyGet(t, context.Background(), front.URL+"/works/"+id, "")
+	}
+	if m := p.Metrics(); m.CachedItems != 3 || m.CachedBytes > 40 {
+		t.Errorf("Expected the cache to stay within CacheBytes, got %d items of %d bytes", m.CachedItems, m.CachedBytes)
+	}
+	if resp, _ := proxyGet(t, context.Background(), front.URL+"/works/W1", ""); resp.Header.Get("X-Cache") != proxy.CacheMiss {
+		t.Errorf("Expected the oldest response to be evicted, got %s", resp.Header.Get("X-Cache"))
+	}
+	if resp, _ := proxyGet(t, context.Background(), front.URL+"/works/W4", ""); resp.Header.Get("X-Cache") != proxy.CacheHit {
+		t.Errorf("Expected the newest response to be cached, got %s", resp.Header.Get("X-Cache"))
+	}
+}
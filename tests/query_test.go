@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"context"
 	"net/http"
 	"testing"
 )
@@ -280,3 +281,31 @@ func TestGetRandom(t *testing.T) {
 		t.Errorf("Unexpected work title: %s", work.Title)
 	}
 }
+
+func TestQueryBuilderAll(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+
+	client := NewTestClient(server.URL)
+
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.URL.Query().Get("cursor") == "*" {
+			return http.StatusOK, `{"results": [` + SampleWorkResponse + `], "meta": {"count": 2, "next_cursor": "page2"}}`
+		}
+		return http.StatusOK, `{"results": [` + SampleWorkResponse + `], "meta": {"count": 2}}`
+	}
+
+	count := 0
+	for work, err := range client.Works().All(context.Background()) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if work.ID != "https://openalex.org/W2741809807" {
+			t.Errorf("Unexpected work ID: %s", work.ID)
+		}
+		count++
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 works across pages, got %d", count)
+	}
+}
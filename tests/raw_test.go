@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+func TestListRaw(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusOK, SamplePaginatedResponse)
+	client := NewTestClient(server.URL)
+
+	results, meta, err := client.Works().Filter("publication_year", 2018).ListRaw(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta == nil || meta.Count != 1 || meta.DBRespTime != 123 {
+		t.Errorf("Unexpected meta: %+v", meta)
+	}
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 raw result, got %d", len(results))
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(results[0], &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["id"] != "https://openalex.org/W2741809807" {
+		t.Errorf("Unexpected raw result: %s", results[0])
+	}
+	// Raw results keep fields the Work model does not capture.
+	if oa, ok := fields["open_access"].(map[string]any); !ok || oa["oa_date"] == nil {
+		t.Errorf("Expected open_access.oa_date in the raw result, got %v", fields["open_access"])
+	}
+}
+
+func TestGetRaw(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.URL.Path != "/works/W2741809807" {
+			t.Errorf("Unexpected path %s", req.URL.Path)
+		}
+		return http.StatusOK, SampleWorkResponse
+	}
+	client := NewTestClient(server.URL)
+
+	raw, err := client.Works().GetRaw(context.Background(), "W2741809807")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var work struct {
+		DOI string `json:"doi"`
+	}
+	if err := json.Unmarshal(raw, &work); err != nil || work.DOI != "https://doi.org/10.7717/peerj.4375" {
+		t.Errorf("Unexpected raw entity %s: %v", raw, err)
+	}
+
+	server.SetResponse(http.StatusNotFound, `{}`)
+	if _, err := client.Works().GetRaw(context.Background(), "W2741809807"); err == nil {
+		t.Error("Expected an error for a missing entity")
+	}
+}
+
+func TestListAs(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if got := req.URL.Query().Get("select"); got != "id,doi,open_access" {
+			t.Errorf("Expected select=id,doi,open_access, got %q", got)
+		}
+		return http.StatusOK, SamplePaginatedResponse
+	}
+	// Strict decoding only checks the models, not caller-supplied types.
+	client := NewTestClient(server.URL, core.WithStrictDecoding())
+
+	type ref struct {
+		ID         string `json:"id"`
+		DOI        string `json:"doi"`
+		OpenAccess struct {
+			OADate string `json:"oa_date"`
+		} `json:"open_access"`
+	}
+	refs, meta, err := core.ListAs[ref](context.Background(), client.Works().Select("id", "doi", "open_access"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta == nil || meta.Count != 1 {
+		t.Errorf("Unexpected meta: %+v", meta)
+	}
+	if len(refs) != 1 || refs[0].ID != "https://openalex.org/W2741809807" || refs[0].DOI == "" {
+		t.Fatalf("Unexpected results: %+v", refs)
+	}
+	if refs[0].OpenAccess.OADate == "" {
+		t.Error("Expected a field the Work model does not capture to decode")
+	}
+}
@@ -0,0 +1,229 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+const driftedWorkResponse = `{
+	"id": "https://openalex.org/W1",
+	"display_name": "Drifted",
+	"fwci_v2": 1.5,
+	"authorships": [
+		{"author": {"id": "https://openalex.org/A1"}, "institution_assertions": []},
+		{"author": {"id": "https://openalex.org/A2"}}
+	],
+	"topics": [{"id": "https://openalex.org/T1", "new_score": 0.5}]
+}`
+
+func TestExtraCapturesUnknownFields(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusOK, driftedWorkResponse)
+	work, err := NewTestClient(server.URL).Works().Get("W1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if work.Extra != nil {
+		t.Errorf("Expected Extra to be left out unless requested, got %v", work.Extra)
+	}
+
+	client := NewTestClient(server.URL, core.WithExtraFields())
+	work, err = client.Works().Get("W1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(work.Extra["fwci_v2"]) != "1.5" {
+		t.Errorf("Expected fwci_v2 in Extra, got %v", work.Extra)
+	}
+	if len(work.Extra) != 1 {
+		t.Errorf("Expected only top-level unknown fields in Extra, got %v", work.Extra)
+	}
+
+	server.SetResponse(http.StatusOK, SampleAuthorResponse)
+	author, err := client.Authors().Get("A5023888391")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if author.Extra != nil {
+		t.Errorf("Expected no Extra for a fully captured entity, got %v", author.Extra)
+	}
+}
+
+func TestExtraSurvivesEncoding(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusOK, driftedWorkResponse)
+	work, err := NewTestClient(server.URL, core.WithExtraFields()).Works().Get("W1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	work.Topics[0].Score = 0.5
+	work.Topics[0].Extra = map[string]json.RawMessage{"new_score": json.RawMessage(`0.5`)}
+
+	data, err := json.Marshal(work)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		DisplayName string           `json:"display_name"`
+		FWCI        float64          `json:"fwci_v2"`
+		Topics      []map[string]any `json:"topics"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.DisplayName != "Drifted" || decoded.FWCI != 1.5 {
+		t.Errorf("Expected model fields and Extra to be encoded, got %s", data)
+	}
+	if len(decoded.Topics) != 1 || decoded.Topics[0]["score"] != 0.5 || decoded.Topics[0]["new_score"] != 0.5 || decoded.Topics[0]["id"] != "https://openalex.org/T1" {
+		t.Errorf("Expected topics to keep their own fields and Extra, got %v", decoded.Topics)
+	}
+
+	ctx := context.Background()
+	s := openTestStore(t)
+	if _, err := s.UpsertWorks(ctx, work); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := s.Work(ctx, "https://openalex.org/W1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stored.Extra["fwci_v2"]) != "1.5" {
+		t.Errorf("Expected the store to keep Extra, got %v", stored.Extra)
+	}
+}
+
+func TestUnmarshalWithExtra(t *testing.T) {
+	var works []*model.Work
+	if err := core.UnmarshalWithExtra([]byte("["+driftedWorkResponse+"]"), &works); err != nil {
+		t.Fatal(err)
+	}
+	if len(works) != 1 || works[0].DisplayName != "Drifted" {
+		t.Fatalf("Unexpected works: %+v", works)
+	}
+	if len(works[0].Extra) != 1 || string(works[0].Extra["fwci_v2"]) != "1.5" {
+		t.Errorf("Expected only the top-level unknown field in Extra, got %v", works[0].Extra)
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		requests.Add(1)
+		return http.StatusOK, driftedWorkResponse
+	}
+	client := NewTestClient(server.URL, core.WithStrictDecoding(), core.WithRetry(2, 0))
+
+	_, err := client.Works().Get("W1")
+	var unknown *core.UnknownFieldsError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected an UnknownFieldsError, got %v", err)
+	}
+	if len(unknown.Entities) != 1 || unknown.Entities[0].Entity != "Work" {
+		t.Fatalf("Expected unknown fields of Work, got %+v", unknown.Entities)
+	}
+	want := []string{"authorships.institution_assertions", "fwci_v2", "topics.new_score"}
+	if !slices.Equal(unknown.Entities[0].Fields, want) {
+		t.Errorf("Expected fields %v, got %v", want, unknown.Entities[0].Fields)
+	}
+	if !strings.Contains(err.Error(), "Work: authorships.institution_assertions, fwci_v2, topics.new_score") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected strict decoding failures not to be retried, got %d requests", requests.Load())
+	}
+}
+
+func TestUnknownFieldHandler(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusOK, `{"meta": {"count": 2, "new_meta": true}, "results": [`+driftedWorkResponse+`, {"id": "W2"}]}`)
+
+	var mu sync.Mutex
+	var reports []core.UnknownFields
+	client := NewTestClient(server.URL, core.WithUnknownFieldHandler(func(u core.UnknownFields) {
+		mu.Lock()
+		defer mu.Unlock()
+		reports = append(reports, u)
+	}))
+
+	works, err := client.Works().List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(works) != 2 || string(works[0].Extra["fwci_v2"]) != "1.5" {
+		t.Fatalf("Expected results to decode with Extra, got %+v", works)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("Expected one report for the drifted work and one for the envelope, got %+v", reports)
+	}
+	if reports[0].Entity != "Work" || len(reports[0].Fields) != 3 {
+		t.Errorf("Unexpected work report: %+v", reports[0])
+	}
+	if reports[1].Entity != "response" || !slices.Equal(reports[1].Fields, []string{"meta.new_meta"}) {
+		t.Errorf("Unexpected envelope report: %+v", reports[1])
+	}
+}
+
+func TestDiffSchemaFixtures(t *testing.T) {
+	unknown, err := core.DiffSchema([]byte(SampleAuthorResponse), new(model.Author))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unknown) != 0 {
+		t.Errorf("Expected the author fixture to match the model, got %+v", unknown)
+	}
+	if _, err := core.DiffSchema([]byte(`{"id": 1}`), new(model.Work)); err == nil {
+		t.Error("Expected an error for a payload that does not decode")
+	}
+}
+
+func TestCLISchemaDiff(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusOK, `{"results": [`+driftedWorkResponse+`, `+driftedWorkResponse+`]}`)
+
+	code, stdout, stderr := runCLI(server, nil, "schema-diff", "works")
+	if code != 1 {
+		t.Fatalf("Expected exit code 1 for drift, got %d: %s", code, stderr)
+	}
+	for _, want := range []string{"Work: 3 unknown fields in 2 records", "fwci_v2", "topics.new_score"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected %q in report:\n%s", want, stdout)
+		}
+	}
+
+	dir := t.TempDir()
+	lines := filepath.Join(dir, "works.jsonl")
+	if err := os.WriteFile(lines, []byte(`{"id": "W1"}`+"\n"+`{"id": "W2", "fwci_v2": 2}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	clean := filepath.Join(dir, "author.json")
+	if err := os.WriteFile(clean, []byte(SampleAuthorResponse), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr = runCLI(server, nil, "schema-diff", "works", lines)
+	if code != 1 || !strings.Contains(stdout, "Work: 1 unknown fields in 2 records") {
+		t.Errorf("Unexpected report for JSON Lines fixture (exit %d): %s%s", code, stdout, stderr)
+	}
+	code, stdout, stderr = runCLI(server, nil, "schema-diff", "authors", clean)
+	if code != 0 || stdout != "no unknown fields in 1 records\n" {
+		t.Errorf("Unexpected report for a clean fixture (exit %d): %s%s", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sunhill666/goalex/pkg/snapshot"
+)
+
+// writeSnapshotPart writes a gzipped JSON Lines partition with the given work IDs.
+func writeSnapshotPart(t *testing.T, root, date, name string, ids ...string) {
+	t.Helper()
+	dir := filepath.Join(root, "data", "works", "updated_date="+date)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	for _, id := range ids {
+		_, _ = fmt.Fprintf(gz, `{"id": "https://openalex.org/%s", "display_name": "Work %s"}`+"\n", id, id)
+	}
+	_ = gz.Close()
+	_ = f.Close()
+}
+
+func newTestSnapshot(t *testing.T, manifestCounts ...int) string {
+	t.Helper()
+	root := t.TempDir()
+	writeSnapshotPart(t, root, "2024-01-01", "part_000.gz", "W1", "W2")
+	writeSnapshotPart(t, root, "2024-02-01", "part_000.gz", "W3")
+
+	manifest := fmt.Sprintf(`{
+		"entries": [
+			{"url": "s3://openalex/data/works/updated_date=2024-01-01/part_000.gz", "meta": {"record_count": %d}},
+			{"url": "s3://openalex/data/works/updated_date=2024-02-01/part_000.gz", "meta": {"record_count": %d}}
+		],
+		"meta": {"record_count": %d}
+	}`, manifestCounts[0], manifestCounts[1], manifestCounts[0]+manifestCounts[1])
+	if err := os.WriteFile(filepath.Join(root, "data", "works", "manifest"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestSnapshotRead(t *testing.T) {
+	snap, err := snapshot.Open(newTestSnapshot(t, 2, 1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	t.Run("all partitions", func(t *testing.T) {
+		seen := map[string]bool{}
+		for w, err := range snap.Works(context.Background(), snapshot.Options{Workers: 2}) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			seen[w.ID] = true
+		}
+		if len(seen) != 3 {
+			t.Errorf("Expected 3 works, got %d", len(seen))
+		}
+	})
+
+	t.Run("since", func(t *testing.T) {
+		count := 0
+		for w, err := range snap.Works(context.Background(), snapshot.Options{Since: "2024-02-01"}) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if w.ID != "https://openalex.org/W3" {
+				t.Errorf("Unexpected work %s", w.ID)
+			}
+			count++
+		}
+		if count != 1 {
+			t.Errorf("Expected 1 work, got %d", count)
+		}
+	})
+
+	t.Run("early break", func(t *testing.T) {
+		for _, err := range snap.Works(context.Background(), snapshot.Options{}) {
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			break
+		}
+	})
+
+	t.Run("missing entity", func(t *testing.T) {
+		for _, err := range snap.Authors(context.Background(), snapshot.Options{}) {
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			t.Error("Expected no authors")
+		}
+	})
+}
+
+func TestSnapshotValidate(t *testing.T) {
+	t.Run("matching manifest", func(t *testing.T) {
+		snap, _ := snapshot.Open(newTestSnapshot(t, 2, 1))
+		report, err := snap.Validate(context.Background(), snapshot.EntityWorks)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !report.OK() || report.Actual != 3 {
+			t.Errorf("Expected valid report with 3 records, got %+v", report.Problems())
+		}
+	})
+
+	t.Run("count mismatch and unlisted file", func(t *testing.T) {
+		root := newTestSnapshot(t, 2, 5)
+		writeSnapshotPart(t, root, "2024-02-01", "part_001.gz", "W4")
+		snap, _ := snapshot.Open(root)
+		report, err := snap.Validate(context.Background(), snapshot.EntityWorks)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		problems := strings.Join(report.Problems(), "\n")
+		if report.OK() || !strings.Contains(problems, "expected 5 records, found 1") || !strings.Contains(problems, "not listed") {
+			t.Errorf("Unexpected problems: %s", problems)
+		}
+	})
+
+	t.Run("blank lines", func(t *testing.T) {
+		root := newTestSnapshot(t, 2, 1)
+		f, err := os.Create(filepath.Join(root, "data", "works", "updated_date=2024-01-01", "part_000.gz"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		gz := gzip.NewWriter(f)
+		_, _ = gz.Write([]byte("\n{\"id\": \"https://openalex.org/W1\"}\n  \t\r\n\n{\"id\": \"https://openalex.org/W2\"}\n\n"))
+		_ = gz.Close()
+		_ = f.Close()
+
+		snap, _ := snapshot.Open(root)
+		report, err := snap.Validate(context.Background(), snapshot.EntityWorks)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !report.OK() || report.Actual != 3 {
+			t.Errorf("Expected blank lines not to count as records, got %d: %v", report.Actual, report.Problems())
+		}
+	})
+
+	t.Run("missing partition", func(t *testing.T) {
+		root := newTestSnapshot(t, 2, 1)
+		_ = os.Remove(filepath.Join(root, "data", "works", "updated_date=2024-01-01", "part_000.gz"))
+		snap, _ := snapshot.Open(root)
+		report, err := snap.Validate(context.Background(), snapshot.EntityWorks)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if report.OK() || !report.Partitions[0].Missing {
+			t.Errorf("Expected missing partition, got %v", report.Problems())
+		}
+	})
+}
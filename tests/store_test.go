@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/store"
+)
+
+const sampleStoreWork = `{
+	"id": "https://openalex.org/W1",
+	"display_name": "Mirrors",
+	"publication_year": 2020,
+	"updated_date": "2024-01-01T00:00:00",
+	"primary_location": {"source": {"id": "https://openalex.org/S1", "display_name": "Journal"}},
+	"primary_topic": {"id": "https://openalex.org/T1", "display_name": "Databases", "score": 0.9},
+	"topics": [{"id": "https://openalex.org/T1", "display_name": "Databases", "score": 0.9}],
+	"reference_works": ["https://openalex.org/W2", "https://openalex.org/W3"],
+	"authorships": [
+		{
+			"author_position": "first",
+			"author": {"id": "https://openalex.org/A1", "display_name": "Ada Lovelace"},
+			"institutions": [{"id": "https://openalex.org/I1", "display_name": "University"}]
+		}
+	]
+}`
+
+func openTestStore(t *testing.T) *store.Store {
+	t.Helper()
+	s, err := store.Open(filepath.Join(t.TempDir(), "mirror.db"))
+	if err != nil {
+		t.Fatalf("Failed to open store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func countRows(t *testing.T, s *store.Store, table string) int {
+	t.Helper()
+	var n int
+	if err := s.DB().QueryRow("SELECT COUNT(*) FROM " + table).Scan(&n); err != nil {
+		t.Fatalf("Failed to count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestStoreUpsertWorks(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	work := &model.Work{}
+	if err := json.Unmarshal([]byte(sampleStoreWork), work); err != nil {
+		t.Fatalf("Failed to decode work: %v", err)
+	}
+
+	changed, err := s.UpsertWorks(ctx, work)
+	if err != nil || changed != 1 {
+		t.Fatalf("Expected 1 change, got %d (%v)", changed, err)
+	}
+
+	for table, expected := range map[string]int{
+		"works":                   1,
+		"authors":                 1,
+		"authorships":             1,
+		"authorship_institutions": 1,
+		"institutions":            1,
+		"sources":                 1,
+		"topics":                  1,
+		"work_topics":             1,
+		"work_references":         2,
+	} {
+		if n := countRows(t, s, table); n != expected {
+			t.Errorf("Expected %d rows in %s, got %d", expected, table, n)
+		}
+	}
+
+	t.Run("same updated date is skipped", func(t *testing.T) {
+		changed, err := s.UpsertWorks(ctx, work)
+		if err != nil || changed != 0 {
+			t.Errorf("Expected no change, got %d (%v)", changed, err)
+		}
+	})
+
+	t.Run("newer updated date replaces children", func(t *testing.T) {
+		newer := *work
+		newer.UpdatedDate = "2024-06-01T00:00:00"
+		newer.ReferenceWorks = []string{"https://openalex.org/W2"}
+		changed, err := s.UpsertWorks(ctx, &newer)
+		if err != nil || changed != 1 {
+			t.Fatalf("Expected 1 change, got %d (%v)", changed, err)
+		}
+		if n := countRows(t, s, "work_references"); n != 1 {
+			t.Errorf("Expected 1 reference, got %d", n)
+		}
+	})
+
+	t.Run("read back", func(t *testing.T) {
+		got, err := s.Work(ctx, "https://openalex.org/W1")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got.DisplayName != "Mirrors" || got.UpdatedDate != "2024-06-01T00:00:00" {
+			t.Errorf("Unexpected work: %+v", got)
+		}
+		works, err := s.Works(ctx, store.WorkFilter{FromYear: 2020, ToYear: 2020})
+		if err != nil || len(works) != 1 {
+			t.Errorf("Expected 1 work for 2020, got %d (%v)", len(works), err)
+		}
+		for _, f := range []store.WorkFilter{
+			{AuthorID: "A1", InstitutionID: "https://openalex.org/I1"},
+			{SourceID: "S1", TopicID: "T1"},
+		} {
+			if works, err := s.Works(ctx, f); err != nil || len(works) != 1 {
+				t.Errorf("Expected 1 work for %+v, got %d (%v)", f, len(works), err)
+			}
+		}
+		for _, f := range []store.WorkFilter{{FromYear: 2021}, {AuthorID: "A2"}, {Type: "x' OR 1=1 --"}} {
+			if works, err := s.Works(ctx, f); err != nil || len(works) != 0 {
+				t.Errorf("Expected no works for %+v, got %d (%v)", f, len(works), err)
+			}
+		}
+	})
+
+	t.Run("dehydrated references are not full entities", func(t *testing.T) {
+		if _, err := s.Author(ctx, "https://openalex.org/A1"); !errors.Is(err, store.ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		author := &model.Author{}
+		if err := json.Unmarshal([]byte(SampleAuthorResponse), author); err != nil {
+			t.Fatalf("Failed to decode author: %v", err)
+		}
+		author.ID = "https://openalex.org/A1"
+		if changed, err := s.UpsertAuthors(ctx, author); err != nil || changed != 1 {
+			t.Fatalf("Expected 1 change, got %d (%v)", changed, err)
+		}
+		got, err := s.Author(ctx, "https://openalex.org/A1")
+		if err != nil || got.WorksCount != 38 {
+			t.Errorf("Unexpected author: %+v (%v)", got, err)
+		}
+	})
+}
+
+func TestStoreSync(t *testing.T) {
+	ctx := context.Background()
+	s := openTestStore(t)
+
+	server := NewTestServer()
+	defer server.Close()
+	client := NewTestClient(server.URL)
+
+	var filters []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		filters = append(filters, req.URL.Query().Get("filter"))
+		return http.StatusOK, `{"results": [` + sampleStoreWork + `], "meta": {"count": 1}}`
+	}
+
+	q := client.Works().Filter("institutions.id", "I1")
+	res, err := store.Sync(ctx, s, "university", q)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.Since != "" || res.Fetched != 1 || res.Changed != 1 {
+		t.Errorf("Unexpected first sync result: %+v", res)
+	}
+
+	res, err = store.Sync(ctx, s, "university", q)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if res.Since == "" || res.Changed != 0 {
+		t.Errorf("Unexpected second sync result: %+v", res)
+	}
+	if len(filters) != 2 || !strings.Contains(filters[1], "from_updated_date:"+res.Since) {
+		t.Errorf("Expected second sync to filter by from_updated_date, got %v", filters)
+	}
+	if _, err := q.List(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filters[2] != "institutions.id:I1" {
+		t.Errorf("Expected Sync to leave the query unchanged, got filter %q", filters[2])
+	}
+
+	if _, err := store.Sync(ctx, s, "keywords", client.Keywords()); err == nil {
+		t.Error("Expected error for unsupported entity type")
+	}
+}
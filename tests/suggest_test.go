@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+	"github.com/Sunhill666/goalex/pkg/suggest"
+)
+
+func completionsResponse(completions ...string) string {
+	return `{"meta": {"count": ` + fmt.Sprint(len(completions)) + `}, "results": [` + strings.Join(completions, ",") + `]}`
+}
+
+func completion(id, name, entityType string, cited int) string {
+	return fmt.Sprintf(`{"id": "https://openalex.org/%s", "display_name": %q, "entity_type": %q, "cited_by_count": %d}`, id, name, entityType, cited)
+}
+
+func TestSuggestDebouncesAndSupersedes(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var queries []string
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		requests.Add(1)
+		queries = append(queries, req.URL.Query().Get("q"))
+		return http.StatusOK, completionsResponse(completion("A1", "Jason Priem", "author", 10))
+	}
+	client := NewTestClient(server.URL)
+	session := suggest.NewSession(client, suggest.Options{Debounce: 50 * time.Millisecond})
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := session.Suggest(context.Background(), "ja")
+		first <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	results, err := session.Suggest(context.Background(), "Jas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-first; !errors.Is(err, suggest.ErrSuperseded) {
+		t.Errorf("Expected the first call to be superseded, got %v", err)
+	}
+	if requests.Load() != 1 || queries[0] != "jas" {
+		t.Errorf("Expected a single request for the latest input, got %v", queries)
+	}
+	if len(results) != 1 || results[0].DisplayName != "Jason Priem" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+}
+
+func TestSuggestCancelsInFlightRequest(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	cancelled := make(chan struct{}, 1)
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.URL.Query().Get("q") == "slow" {
+			<-req.Context().Done()
+			cancelled <- struct{}{}
+			return http.StatusServiceUnavailable, `{}`
+		}
+		return http.StatusOK, completionsResponse()
+	}
+	client := NewTestClient(server.URL)
+	session := suggest.NewSession(client, suggest.Options{Debounce: -1})
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := session.Suggest(context.Background(), "slow")
+		first <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	if _, err := session.Suggest(context.Background(), "slower"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-first:
+		if !errors.Is(err, suggest.ErrSuperseded) {
+			t.Errorf("Expected ErrSuperseded, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the superseded call to return")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the superseded request to be cancelled")
+	}
+}
+
+func TestSuggestPrefixCache(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var queries []string
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		q := req.URL.Query().Get("q")
+		queries = append(queries, q)
+		switch q {
+		case "m":
+			// OpenAlex counts more matches than it lists, so longer queries
+			// may have matches not listed here.
+			return http.StatusOK, `{"meta": {"count": 40}, "results": [` + completion("A9", "Maria Curie", "author", 9) + `]}`
+		case "un":
+			return http.StatusOK, completionsResponse(
+				`{"id": "https://openalex.org/I2", "display_name": "Universität Wien", "hint": "Vienna, Austria", "entity_type": "institution"}`,
+				completion("I3", "University of Oslo", "institution", 20),
+			)
+		}
+		return http.StatusOK, completionsResponse(
+			completion("A1", "Jason Priem", "author", 10),
+			completion("A2", "Jasmine Ortiz", "author", 50),
+			completion("I1", "Jagiellonian University", "institution", 90),
+		)
+	}
+	client := NewTestClient(server.URL)
+	session := suggest.NewSession(client, suggest.Options{Debounce: -1})
+	ctx := context.Background()
+
+	if _, err := session.Suggest(ctx, "ja"); err != nil {
+		t.Fatal(err)
+	}
+	again, err := session.Suggest(ctx, "JA ")
+	if err != nil || len(again) != 3 {
+		t.Fatalf("Expected the cached completions, got %v, %v", again, err)
+	}
+	narrowed, err := session.Suggest(ctx, "jaso")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(narrowed) != 1 || narrowed[0].DisplayName != "Jason Priem" {
+		t.Errorf("Expected the cached completions narrowed to Jason, got %+v", narrowed)
+	}
+	if len(queries) != 1 {
+		t.Errorf("Expected cached and narrowed queries not to hit OpenAlex, got %v", queries)
+	}
+
+	if _, err := session.Suggest(ctx, "m"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.Suggest(ctx, "ma"); err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 3 || queries[2] != "ma" {
+		t.Errorf("Expected a request when the shorter prefix had uncounted matches, got %v", queries)
+	}
+
+	// Narrowing also matches hints.
+	if _, err := session.Suggest(ctx, "un"); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{"un vie", "univ", "universi"} {
+		narrowed, err := session.Suggest(ctx, q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q == "un vie" && (len(narrowed) != 1 || narrowed[0].DisplayName != "Universität Wien") {
+			t.Errorf("Expected the hint to match %q, got %+v", q, narrowed)
+		}
+		if q == "universi" && len(narrowed) != 2 {
+			t.Errorf("Expected %q to narrow the complete answer of un, got %+v", q, narrowed)
+		}
+	}
+	if len(queries) != 4 {
+		t.Errorf("Expected narrowed queries not to hit OpenAlex, got %v", queries)
+	}
+}
+
+func TestSuggestMergesAndRanksEndpoints(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		switch req.URL.Path {
+		case "/autocomplete/authors":
+			return http.StatusOK, completionsResponse(
+				completion("A1", "Jon Harvard Smith", "author", 500),
+				completion("A2", "Harvey Low", "author", 5),
+			)
+		case "/autocomplete/institutions":
+			return http.StatusOK, completionsResponse(
+				completion("I1", "Harvard University", "institution", 900),
+				completion("I2", "Harvard Medical School", "institution", 100),
+				completion("A1", "Jon Harvard Smith", "author", 500),
+			)
+		}
+		t.Errorf("Unexpected path %s", req.URL.Path)
+		return http.StatusNotFound, `{}`
+	}
+	client := NewTestClient(server.URL)
+	session := suggest.NewSession(client, suggest.Options{
+		Endpoints: []string{core.EndpointAuthors, core.EndpointInstitutions},
+		Debounce:  -1,
+		Limit:     3,
+	})
+
+	results, err := session.Suggest(context.Background(), "harv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range results {
+		names = append(names, r.DisplayName)
+	}
+	want := "Harvard University,Harvard Medical School,Harvey Low"
+	if strings.Join(names, ",") != want {
+		t.Errorf("Expected %s, got %s", want, strings.Join(names, ","))
+	}
+}
+
+func TestSuggestMinLength(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		requests.Add(1)
+		return http.StatusOK, completionsResponse()
+	}
+	client := NewTestClient(server.URL)
+	session := suggest.NewSession(client, suggest.Options{Debounce: -1, MinLength: 3})
+
+	results, err := session.Suggest(context.Background(), " ab ")
+	if err != nil || results == nil || len(results) != 0 || requests.Load() != 0 {
+		t.Errorf("Expected no request below the minimum length, got %v, %v, %d requests", results, err, requests.Load())
+	}
+}
@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+type recordedSpan struct {
+	name  string
+	attrs map[string]any
+	err   error
+	ended bool
+}
+
+func (s *recordedSpan) SetAttributes(attrs ...core.Attribute) {
+	for _, a := range attrs {
+		s.attrs[a.Key] = a.Value
+	}
+}
+func (s *recordedSpan) RecordError(err error) { s.err = err }
+func (s *recordedSpan) End()                  { s.ended = true }
+
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string, attrs ...core.Attribute) (context.Context, core.Span) {
+	s := &recordedSpan{name: name, attrs: make(map[string]any)}
+	s.SetAttributes(attrs...)
+	t.spans = append(t.spans, s)
+	return ctx, s
+}
+
+type recordingMeter struct {
+	mu       sync.Mutex
+	counters map[string]int64
+	values   map[string][]float64
+	attrs    map[string][]core.Attribute
+}
+
+func newRecordingMeter() *recordingMeter {
+	return &recordingMeter{counters: map[string]int64{}, values: map[string][]float64{}, attrs: map[string][]core.Attribute{}}
+}
+
+func (m *recordingMeter) Add(ctx context.Context, name string, delta int64, attrs ...core.Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name] += delta
+	m.attrs[name] = attrs
+}
+
+func (m *recordingMeter) Record(ctx context.Context, name string, value float64, attrs ...core.Attribute) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[name] = append(m.values[name], value)
+}
+
+func TestTracerSpansPerOperation(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.URL.Path == "/authors/A5023888391" {
+			return http.StatusOK, SampleAuthorResponse
+		}
+		return http.StatusOK, `{"results": [{"id": "W1"}, {"id": "W2"}], "meta": {"count": 42, "db_response_time_ms": 17, "next_cursor": "abc"}}`
+	}
+
+	tracer := &recordingTracer{}
+	client := NewTestClient(server.URL, core.WithTracer(tracer))
+
+	if _, err := client.Works().Filter("publication_year", 2020).Filter("is_oa", true).List(); err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if _, err := client.Authors().Get("A5023888391"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	q := client.Works()
+	_, next, _ := q.CursorWithContext(context.Background())
+	_, _, _ = q.CursorWithContext(context.Background(), next)
+
+	if len(tracer.spans) != 4 {
+		t.Fatalf("Expected 4 spans, got %d", len(tracer.spans))
+	}
+	list := tracer.spans[0]
+	if list.name != "Works.List" || !list.ended {
+		t.Errorf("Unexpected list span: %+v", list)
+	}
+	if keys, _ := list.attrs[core.AttrFilterKeys].([]string); !slices.Equal(keys, []string{"is_oa", "publication_year"}) {
+		t.Errorf("Unexpected filter keys: %v", list.attrs[core.AttrFilterKeys])
+	}
+	if list.attrs[core.AttrEndpoint] != "/works" || list.attrs[core.AttrResultCount] != 2 ||
+		list.attrs[core.AttrTotalCount] != 42 || list.attrs[core.AttrDBResponseTime] != 17 {
+		t.Errorf("Unexpected list attributes: %v", list.attrs)
+	}
+	if tracer.spans[1].name != "Authors.Get" || tracer.spans[1].attrs[core.AttrResultCount] != 1 {
+		t.Errorf("Unexpected get span: %+v", tracer.spans[1])
+	}
+	if tracer.spans[2].name != "Works.Cursor" || tracer.spans[2].attrs[core.AttrCursorPage] != 1 || tracer.spans[3].attrs[core.AttrCursorPage] != 2 {
+		t.Errorf("Unexpected cursor spans: %+v %+v", tracer.spans[2], tracer.spans[3])
+	}
+}
+
+func TestTracerRecordsErrors(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.SetResponse(http.StatusNotFound, `{}`)
+
+	tracer := &recordingTracer{}
+	client := NewTestClient(server.URL, core.WithTracer(tracer))
+	if _, err := client.Institutions().Get("I0"); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(tracer.spans) != 1 || tracer.spans[0].err == nil || !tracer.spans[0].ended {
+		t.Errorf("Expected an ended span with the error, got %+v", tracer.spans)
+	}
+}
+
+func TestMeterRecordsRequestsAndRetries(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	var requests atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if requests.Add(1) == 1 {
+			return http.StatusTooManyRequests, `{}`
+		}
+		return http.StatusOK, SampleWorkResponse
+	}
+
+	meter := newRecordingMeter()
+	client := NewTestClient(server.URL, core.WithMeter(meter))
+	if _, err := client.Works().Get("W2741809807"); err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+
+	if meter.counters[core.MetricRequests] != 2 || meter.counters[core.MetricRetries] != 1 {
+		t.Errorf("Unexpected counters: %v", meter.counters)
+	}
+	if len(meter.values[core.MetricDuration]) != 2 {
+		t.Errorf("Expected 2 latency measurements, got %v", meter.values[core.MetricDuration])
+	}
+	attrs := meter.attrs[core.MetricRequests]
+	if !slices.Contains(attrs, core.Attribute{Key: core.AttrEndpoint, Value: "/works/{id}"}) ||
+		!slices.Contains(attrs, core.Attribute{Key: core.AttrStatusCode, Value: 200}) {
+		t.Errorf("Unexpected request attributes: %v", attrs)
+	}
+	if !slices.Contains(meter.attrs[core.MetricRetries], core.Attribute{Key: core.AttrRetryReason, Value: "status 429"}) {
+		t.Errorf("Unexpected retry attributes: %v", meter.attrs[core.MetricRetries])
+	}
+}
+
+func TestMeterEndpointLabels(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if !strings.HasPrefix(req.URL.Path, "/openalex/") {
+			t.Errorf("Expected requests below the base URL's path, got %s", req.URL.Path)
+		}
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/ngrams"):
+			return http.StatusOK, `{"meta": {"count": 0}, "ngrams": []}`
+		case req.URL.Path == "/openalex/works":
+			return http.StatusOK, SamplePaginatedResponse
+		default:
+			return http.StatusOK, SampleWorkResponse
+		}
+	}
+
+	meter := newRecordingMeter()
+	client := NewTestClient(server.URL+"/openalex", core.WithMeter(meter))
+	ctx := context.Background()
+	for _, tt := range []struct {
+		endpoint string
+		call     func() error
+	}{
+		{"/works/{id}", func() error { _, err := client.Works().Get("W2741809807"); return err }},
+		{"/works/{id}", func() error { _, err := client.Works().Get("https://doi.org/10.7717/peerj.4375"); return err }},
+		{"/works/{id}/ngrams", func() error { _, err := client.Works().NGrams(ctx, "W2741809807"); return err }},
+		{"/works", func() error { _, err := client.Works().List(); return err }},
+	} {
+		_ = tt.call()
+		if attrs := meter.attrs[core.MetricRequests]; !slices.Contains(attrs, core.Attribute{Key: core.AttrEndpoint, Value: tt.endpoint}) {
+			t.Errorf("Expected endpoint %s, got %v", tt.endpoint, attrs)
+		}
+	}
+}
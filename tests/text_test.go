@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Sunhill666/goalex/pkg/core"
+)
+
+const sampleTextResponse = `{
+	"meta": {"keywords_count": 1, "topics_count": 1, "concepts_count": 1},
+	"keywords": [{"id": "https://openalex.org/keywords/grant-proposal", "display_name": "Grant Proposal", "score": 0.61}],
+	"primary_topic": {"id": "https://openalex.org/T11636", "display_name": "Research Funding", "score": 0.98,
+		"subfield": {"id": "https://openalex.org/subfields/3312", "display_name": "Sociology and Political Science"}},
+	"topics": [{"id": "https://openalex.org/T11636", "display_name": "Research Funding", "score": 0.98}],
+	"concepts": [{"id": "https://openalex.org/C41008148", "display_name": "Computer science", "score": 0.45, "level": 0}]
+}`
+
+func TestClassifyTextGet(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.Method != http.MethodGet || req.URL.Path != "/text" {
+			t.Errorf("Expected GET /text, got %s %s", req.Method, req.URL.Path)
+		}
+		if req.URL.Query().Get("title") != "Funding research" || req.URL.Query().Get("abstract") != "Short abstract" {
+			t.Errorf("Unexpected query: %s", req.URL.RawQuery)
+		}
+		return http.StatusOK, sampleTextResponse
+	}
+	client := NewTestClient(server.URL)
+
+	result, err := client.ClassifyText(context.Background(), "Funding research", "Short abstract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PrimaryTopic == nil || result.PrimaryTopic.DisplayName != "Research Funding" || result.PrimaryTopic.Score != 0.98 {
+		t.Errorf("Unexpected primary topic: %+v", result.PrimaryTopic)
+	}
+	if result.PrimaryTopic.Subfield == nil {
+		t.Error("Expected the primary topic to carry its subfield")
+	}
+	if len(result.Topics) != 1 || len(result.Keywords) != 1 || result.Keywords[0].Score != 0.61 {
+		t.Errorf("Unexpected topics or keywords: %+v", result)
+	}
+	if len(result.Concepts) != 1 || result.Concepts[0].DisplayName != "Computer science" {
+		t.Errorf("Unexpected concepts: %+v", result.Concepts)
+	}
+	if result.Meta == nil || result.Meta.TopicsCount != 1 {
+		t.Errorf("Unexpected meta: %+v", result.Meta)
+	}
+
+	if _, err := client.ClassifyText(context.Background(), "", ""); err == nil {
+		t.Error("Expected an error without title and abstract")
+	}
+}
+
+func TestClassifyTextPostForLongAbstracts(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	abstract := strings.Repeat("grant proposals describe planned research in detail. ", 100)
+	var attempts atomic.Int32
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.Method != http.MethodPost || req.URL.Path != "/text" {
+			t.Errorf("Expected POST /text, got %s %s", req.Method, req.URL.Path)
+		}
+		if req.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a JSON body, got %q", req.Header.Get("Content-Type"))
+		}
+		if req.URL.Query().Get("mailto") != "me@example.com" {
+			t.Errorf("Expected the polite pool parameter, got %s", req.URL.RawQuery)
+		}
+		data, _ := io.ReadAll(req.Body)
+		var doc core.TextDocument
+		if err := json.Unmarshal(data, &doc); err != nil || doc.Title != "Proposal" || doc.Abstract != abstract {
+			t.Errorf("Unexpected body on attempt %d: %s", attempts.Load()+1, data)
+		}
+		// The body is sent again when the request is retried.
+		if attempts.Add(1) == 1 {
+			return http.StatusServiceUnavailable, `{}`
+		}
+		return http.StatusOK, sampleTextResponse
+	}
+	client := NewTestClient(server.URL, core.PolitePool("me@example.com"), core.WithRetry(1, 0))
+
+	result, err := client.ClassifyText(context.Background(), "Proposal", abstract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Topics) != 1 || attempts.Load() != 2 {
+		t.Errorf("Unexpected result after %d attempts: %+v", attempts.Load(), result)
+	}
+}
+
+func TestClassifyTextBatch(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if req.URL.Query().Get("title") == "broken" {
+			return http.StatusBadRequest, `{}`
+		}
+		return http.StatusOK, sampleTextResponse
+	}
+	client := NewTestClient(server.URL)
+
+	docs := []core.TextDocument{{Title: "first"}, {Title: "broken"}, {Abstract: "third"}}
+	results := client.ClassifyTextBatch(context.Background(), docs, core.BatchOptions{Concurrency: 2})
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	for i, r := range results {
+		if r.Document != docs[i] {
+			t.Errorf("Expected results in input order, got %+v at %d", r.Document, i)
+		}
+	}
+	if results[0].Err != nil || results[0].Classification.PrimaryTopic == nil {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+	if results[1].Err == nil {
+		t.Error("Expected the broken document to fail")
+	}
+	if results[2].Err != nil {
+		t.Errorf("Unexpected error for an abstract-only document: %v", results[2].Err)
+	}
+}
@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/Sunhill666/goalex/internal/model"
+	"github.com/Sunhill666/goalex/pkg/trends"
+)
+
+func seriesValues(s *trends.Series) string {
+	var out []string
+	for _, p := range s.Points {
+		out = append(out, fmt.Sprintf("%d:%g", p.Year, p.Value))
+	}
+	return fmt.Sprint(out)
+}
+
+func TestFromCountsFillsGaps(t *testing.T) {
+	counts := []*model.CountsByYear{
+		{Year: 2023, WorksCount: 4, CitedByCount: 40},
+		{Year: 2020, WorksCount: 1, CitedByCount: 10},
+		{Year: 2021, WorksCount: 2, CitedByCount: 20},
+	}
+	works := trends.FromCounts("A1", counts, trends.Works)
+	if got := seriesValues(works); got != "[2020:1 2021:2 2022:0 2023:4]" {
+		t.Errorf("Unexpected works series: %s", got)
+	}
+	citations := trends.FromCounts("A1", counts, trends.Citations)
+	if citations.Total() != 70 || citations.Value(2022) != 0 || citations.Value(2019) != 0 || citations.Value(2023) != 40 {
+		t.Errorf("Unexpected citations series: %s", seriesValues(citations))
+	}
+	if got := seriesValues(works.Range(2019, 2021)); got != "[2019:0 2020:1 2021:2]" {
+		t.Errorf("Unexpected range: %s", got)
+	}
+	if got := seriesValues(works.Cumulative()); got != "[2020:1 2021:3 2022:3 2023:7]" {
+		t.Errorf("Unexpected cumulative series: %s", got)
+	}
+	if empty := trends.FromCounts("none", nil, trends.Works); len(empty.Points) != 0 || empty.First() != 0 {
+		t.Errorf("Expected an empty series, got %s", seriesValues(empty))
+	}
+}
+
+func TestGrowthStatistics(t *testing.T) {
+	s := trends.New("s", map[int]float64{2018: 100, 2019: 150, 2020: 0, 2021: 90, 2022: 400})
+
+	growth := s.Growth()
+	if len(growth.Points) != 4 || growth.Points[0].Value != 0.5 || growth.Points[1].Value != -1 || !math.IsNaN(growth.Points[2].Value) {
+		t.Errorf("Unexpected growth: %s", seriesValues(growth))
+	}
+	cagr, err := s.CAGR()
+	if err != nil || math.Abs(cagr-math.Sqrt2+1) > 1e-9 {
+		t.Errorf("Expected a CAGR of sqrt(2)-1, got %v, %v", cagr, err)
+	}
+	if _, err := s.Range(2020, 2022).CAGR(); !errors.Is(err, trends.ErrInsufficientData) {
+		t.Errorf("Expected ErrInsufficientData for a series starting at zero, got %v", err)
+	}
+	if _, err := s.Range(2020, 2020).CAGR(); !errors.Is(err, trends.ErrInsufficientData) {
+		t.Errorf("Expected ErrInsufficientData for a single year, got %v", err)
+	}
+	if got := seriesValues(s.MovingAverage(3)); got != "[2020:83.33333333333333 2021:80 2022:163.33333333333334]" {
+		t.Errorf("Unexpected moving average: %s", got)
+	}
+	if got := s.MovingAverage(6); len(got.Points) != 0 {
+		t.Errorf("Expected no points for a window longer than the series, got %s", seriesValues(got))
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := trends.New("a", map[int]float64{2020: 10, 2021: 20, 2022: 40})
+	b := trends.New("b", map[int]float64{2021: 30, 2022: 40, 2023: 50})
+	c := trends.New("c", nil)
+
+	cmp := trends.Compare(a, b, c)
+	if cmp.From != 2020 || cmp.To != 2023 || len(cmp.Series) != 3 {
+		t.Fatalf("Unexpected comparison range: %d-%d", cmp.From, cmp.To)
+	}
+	if got := seriesValues(cmp.Series[1]); got != "[2020:0 2021:30 2022:40 2023:50]" {
+		t.Errorf("Unexpected aligned series: %s", got)
+	}
+	if got := fmt.Sprint(cmp.Shares(2022)); got != "[0.5 0.5 0]" {
+		t.Errorf("Unexpected shares: %s", got)
+	}
+	if got := fmt.Sprint(cmp.Shares(2019)); got != "[0 0 0]" {
+		t.Errorf("Unexpected shares without activity: %s", got)
+	}
+
+	ranked := cmp.RankByCAGR()
+	if ranked[0].Series.Name != "a" || ranked[1].Series.Name != "b" || ranked[2].Series.Name != "c" {
+		t.Errorf("Unexpected ranking: %s, %s, %s", ranked[0].Series.Name, ranked[1].Series.Name, ranked[2].Series.Name)
+	}
+	if math.Abs(ranked[0].CAGR-1) > 1e-9 || !errors.Is(ranked[2].Err, trends.ErrInsufficientData) {
+		t.Errorf("Unexpected rankings: %+v %+v", ranked[0], ranked[2])
+	}
+
+	// A collapsing series is not ranked as growing.
+	collapsed := trends.New("collapsed", map[int]float64{2020: 10, 2021: 20, 2022: 0, 2023: 0})
+	steady := trends.New("steady", map[int]float64{2021: 0, 2022: 10, 2023: 11})
+	ranked = trends.Compare(collapsed, steady).RankByCAGR()
+	if ranked[0].Series.Name != "steady" || math.Abs(ranked[0].CAGR-0.1) > 1e-9 {
+		t.Errorf("Expected the steady series first, measured from its first value, got %+v", ranked[0])
+	}
+	if ranked[1].Series.Name != "collapsed" || !errors.Is(ranked[1].Err, trends.ErrInsufficientData) {
+		t.Errorf("Expected the collapsed series to have no CAGR, got %+v", ranked[1])
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	server := NewTestServer()
+	defer server.Close()
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if q := req.URL.Query(); q.Get("group_by") != "publication_year" || q.Get("filter") != "publication_year:2019-2022" {
+			t.Errorf("Unexpected query: %s", req.URL.RawQuery)
+		}
+		return http.StatusOK, `{"meta": {"count": 60}, "group_by": [
+			{"key": "2022", "key_display_name": "2022", "count": 30},
+			{"key": "2019", "key_display_name": "2019", "count": 10},
+			{"key": "2020", "key_display_name": "2020", "count": 20}]}`
+	}
+	client := NewTestClient(server.URL)
+
+	q := client.Works().Filter("publication_year", "2019-2022")
+	s, err := trends.FromQuery(context.Background(), "works", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := seriesValues(s); got != "[2019:10 2020:20 2021:0 2022:30]" {
+		t.Errorf("Unexpected series: %s", got)
+	}
+
+	// The query is grouped on a copy.
+	server.ResponseHandler = func(req *http.Request) (int, string) {
+		if q := req.URL.Query(); q.Get("group_by") != "" {
+			t.Errorf("Expected FromQuery to leave the query unchanged, got %s", req.URL.RawQuery)
+		}
+		return http.StatusOK, `{"meta": {"count": 0}, "results": []}`
+	}
+	if _, err := q.List(); err != nil {
+		t.Fatal(err)
+	}
+}